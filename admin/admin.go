@@ -0,0 +1,173 @@
+// Package admin provides a net/http handler to inspect the services of a gobs.Bootstrap.
+//
+// The handler can be mounted on any existing mux:
+//
+//	mux.Handle("/admin/", http.StripPrefix("/admin", admin.NewHandler(bs)))
+//
+// It serves the following endpoints:
+//
+//	GET /services  status, dependencies, followers, phase timings and last error of all services
+//	GET /health    health report of all services
+//	GET /livez     200 if no service failed its health check, 503 otherwise
+//	GET /readyz    200 if all services are started and ready, 503 otherwise
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/common"
+)
+
+type Handler struct {
+	bs  *gobs.Bootstrap
+	mux *http.ServeMux
+}
+
+var _ http.Handler = (*Handler)(nil)
+
+// NewHandler creates a handler serving the admin endpoints of the bootstrap.
+func NewHandler(bs *gobs.Bootstrap) *Handler {
+	h := &Handler{
+		bs:  bs,
+		mux: http.NewServeMux(),
+	}
+	h.mux.HandleFunc("GET /services", h.services)
+	h.mux.HandleFunc("GET /health", h.health)
+	h.mux.HandleFunc("GET /livez", h.livez)
+	h.mux.HandleFunc("GET /readyz", h.readyz)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+type timingJSON struct {
+	StartedAt time.Time `json:"startedAt"`
+	EndedAt   time.Time `json:"endedAt"`
+	Duration  string    `json:"duration"`
+	Error     string    `json:"error,omitempty"`
+}
+
+type serviceJSON struct {
+	Key          string                              `json:"key"`
+	Status       common.ServiceStatus                `json:"status"`
+	Dependencies []string                            `json:"dependencies"`
+	Followers    []string                            `json:"followers"`
+	Timings      map[common.ServiceStatus]timingJSON `json:"timings"`
+	LastError    string                              `json:"lastError,omitempty"`
+}
+
+type serviceHealthJSON struct {
+	Key       string            `json:"key"`
+	Status    gobs.HealthStatus `json:"status"`
+	Started   bool              `json:"started"`
+	Live      bool              `json:"live"`
+	Ready     bool              `json:"ready"`
+	Error     string            `json:"error,omitempty"`
+	Unhealthy []string          `json:"unhealthy,omitempty"`
+	Duration  string            `json:"duration"`
+}
+
+type healthJSON struct {
+	Status    gobs.HealthStatus   `json:"status"`
+	Started   bool                `json:"started"`
+	Live      bool                `json:"live"`
+	Ready     bool                `json:"ready"`
+	CheckedAt time.Time           `json:"checkedAt"`
+	Services  []serviceHealthJSON `json:"services"`
+}
+
+func (h *Handler) services(w http.ResponseWriter, r *http.Request) {
+	infos := h.bs.Services()
+	out := make([]serviceJSON, 0, len(infos))
+	for _, info := range infos {
+		s := serviceJSON{
+			Key:          info.Key,
+			Status:       info.Status,
+			Dependencies: nonNil(info.Dependencies),
+			Followers:    nonNil(info.Followers),
+			Timings:      make(map[common.ServiceStatus]timingJSON, len(info.Timings)),
+			LastError:    errorString(info.LastError),
+		}
+		for ss, timing := range info.Timings {
+			s.Timings[ss] = timingJSON{
+				StartedAt: timing.StartedAt,
+				EndedAt:   timing.EndedAt,
+				Duration:  timing.Duration().String(),
+				Error:     errorString(timing.Err),
+			}
+		}
+		out = append(out, s)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (h *Handler) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, toHealthJSON(h.bs.Health(r.Context())))
+}
+
+func (h *Handler) livez(w http.ResponseWriter, r *http.Request) {
+	report := h.bs.Health(r.Context())
+	code := http.StatusOK
+	if !report.Live() {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, toHealthJSON(report))
+}
+
+func (h *Handler) readyz(w http.ResponseWriter, r *http.Request) {
+	report := h.bs.Health(r.Context())
+	code := http.StatusOK
+	if !report.Ready() {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, toHealthJSON(report))
+}
+
+func toHealthJSON(report *gobs.HealthReport) healthJSON {
+	out := healthJSON{
+		Status:    report.Status,
+		Started:   report.Started(),
+		Live:      report.Live(),
+		Ready:     report.Ready(),
+		CheckedAt: report.CheckedAt,
+		Services:  make([]serviceHealthJSON, 0, len(report.Services)),
+	}
+	for _, sh := range report.Services {
+		out.Services = append(out.Services, serviceHealthJSON{
+			Key:       sh.Key,
+			Status:    sh.Status,
+			Started:   sh.Started,
+			Live:      sh.Live,
+			Ready:     sh.Ready,
+			Error:     errorString(sh.Err),
+			Unhealthy: sh.Unhealthy,
+			Duration:  sh.Duration.String(),
+		})
+	}
+	return out
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
@@ -3,8 +3,12 @@ package gobs
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -18,10 +22,23 @@ import (
 type Bootstrap struct {
 	*logger.Logger
 	numOfConcurrencies int
+	enableRollback     bool
+	defaultTimeout     time.Duration
+	shutdownTimeout    time.Duration
+	forceExit          bool
+	interrupted        atomic.Bool
+	events             eventBus
+	bindings           map[reflect.Type]string
+	enableInjection    bool
+	resourceLimits     map[string]int
+	profiles           map[common.ServiceStatus]*phaseProfile
+	profileMutex       sync.Mutex
 	schedulers         map[common.ServiceStatus]*scheduler.Scheduler
 	services           []*Service
 	keys               map[string]*Service
 	errno              int
+	// schedMutex guards schedulers and errno, Interrupt may be called while phases are running
+	schedMutex sync.Mutex
 }
 
 // NewBootstrap creates a new Bootstrap instance using the provided configurations.
@@ -36,7 +53,18 @@ func NewBootstrap(configs ...Config) *Bootstrap {
 		Logger:             logger.NewLog(cfg.Logger),
 		schedulers:         make(map[common.ServiceStatus]*scheduler.Scheduler, common.StatusStop+1),
 		numOfConcurrencies: cfg.NumOfConcurrencies,
+		enableRollback:     cfg.EnableRollback,
+		defaultTimeout:     cfg.DefaultTimeout,
+		shutdownTimeout:    cfg.ShutdownTimeout,
+		forceExit:          cfg.ForceExitOnSecondSignal,
+		enableInjection:    cfg.EnableInjection,
+		resourceLimits:     cfg.ResourceLimits,
 		keys:               make(map[string]*Service),
+		bindings:           make(map[reflect.Type]string),
+		profiles:           make(map[common.ServiceStatus]*phaseProfile, common.StatusStop+1),
+	}
+	if bs.shutdownTimeout <= 0 {
+		bs.shutdownTimeout = DEFAULT_SHUTDOWN_TIMEOUT
 	}
 	bs.SetDetail(cfg.EnableLogDetail)
 	bs.SetTag("Bootstrap")
@@ -46,6 +74,7 @@ func NewBootstrap(configs ...Config) *Bootstrap {
 func (bs *Bootstrap) Deinit(ctx context.Context) {
 	bs.keys = nil
 	bs.services = nil
+	bs.interrupted.Store(false)
 }
 
 // GetService returns the service instance with the provided original instance or key.
@@ -61,7 +90,7 @@ func GetService[T IService](bs *Bootstrap, service T, key string) (*T, bool) {
 		key = utils.DefaultServiceName(service)
 	}
 	if cp, ok := bs.keys[key]; ok {
-		res, ok := cp.instance.(*T)
+		res, ok := cp.getInstance().(*T)
 		return res, ok
 	}
 	return &service, false
@@ -117,9 +146,18 @@ func (bs *Bootstrap) Add(s IService, status common.ServiceStatus, key string) er
 		return nil
 	}
 	sBlock := NewService(s, key, status, bs.Logger.Clone())
+	sBlock.defaultTimeout = bs.defaultTimeout
+	if bs.enableInjection {
+		injects, err := parseInjectFields(s)
+		if err != nil {
+			return err
+		}
+		sBlock.injects = injects
+	}
 	bs.keys[key] = sBlock
 	bs.services = append(bs.services, sBlock)
 	bs.LogS("Service %s is added with status %s", utils.CompactName(key), status.String())
+	bs.emit(Event{Type: EventServiceAdded, Service: key, Phase: status})
 	return nil
 }
 
@@ -137,7 +175,7 @@ func (bs *Bootstrap) Init(ctx context.Context) error {
 		sb := bs.services[i]
 		taskKey := utils.CompactName(sb.name)
 		unTag := bs.AddTag(taskKey)
-		if inst, ok := sb.instance.(IServiceInit); ok {
+		if inst, ok := sb.instance.(IServiceInit); ok && !sb.supplied {
 			sCfg, err := inst.Init(ctx)
 			if err != nil {
 				bs.LogS("Failed to init %s", taskKey, err.Error())
@@ -151,10 +189,35 @@ func (bs *Bootstrap) Init(ctx context.Context) error {
 			}
 		}
 
+		if err := bs.addInjectedDependencies(sb); err != nil {
+			bs.LogS("Failed to set injected dependencies of %s, %s", sb.name, err.Error())
+			return err
+		}
+
 		tasks = append(tasks, sb)
 		totalLength = len(bs.services)
 		unTag()
 	}
+	if err := bs.linkServices(); err != nil {
+		bs.LogS("Failed to link dependencies, %s", err.Error())
+		return err
+	}
+	if err := scheduler.DetectCycle(tasks, func(task types.ITask) []types.ITask {
+		return task.(*Service).following
+	}); err != nil {
+		bs.LogS("Failed to build dependencies, %s", err.Error())
+		return err
+	}
+	if err := bs.checkScopes(); err != nil {
+		bs.LogS("Failed to check scopes, %s", err.Error())
+		return err
+	}
+	for _, ss := range []common.ServiceStatus{common.StatusSetup, common.StatusStart, common.StatusStop} {
+		if err := scheduler.CheckResources(bs.resourceLimits, ss, tasks...); err != nil {
+			bs.LogS("Failed to check resources, %s", err.Error())
+			return err
+		}
+	}
 	return bs.execute(ctx, common.StatusInit, tasks, 0)
 }
 
@@ -162,8 +225,10 @@ func (bs *Bootstrap) Init(ctx context.Context) error {
 // It must be called before Start(...) method. Results of setup process (internnally) will be used in Start(...) method.
 // Make sure that the Init(...) method is fisnished before calling this method. Otherwise, it will interrupt the Init(...) process
 // and return an error as Init(...) method is not finished.
+// If services fail to setup, the returned error is a *common.LifecycleError which reports every failed service
+// and the services skipped because of them.
 func (bs *Bootstrap) Setup(ctx context.Context) error {
-	sched, ok := bs.schedulers[common.StatusInit]
+	sched, ok := bs.scheduler(common.StatusInit)
 	if !ok {
 		return errors.New("Init is not executed")
 	}
@@ -186,7 +251,7 @@ func (bs *Bootstrap) Setup(ctx context.Context) error {
 // If you set other services depended on pending services, make sure that the pending service has it own goroutine
 // to handle the pending states and OnStart function must return. It's not recommended to use Start() method for this purpose.
 func (bs *Bootstrap) Start(ctx context.Context) error {
-	sched, ok := bs.schedulers[common.StatusSetup]
+	sched, ok := bs.scheduler(common.StatusSetup)
 	if !ok {
 		return errors.New("Setup is not executed")
 	}
@@ -200,16 +265,17 @@ func (bs *Bootstrap) Start(ctx context.Context) error {
 	return bs.execute(ctx, common.StatusStart, tasks, bs.numOfConcurrencies)
 }
 
-// Stop method is used to stop all services which have been setup successfully.
+// Stop method is used to stop all services which have been setup successfully and not yet stopped by a rollback.
 // This method will try to interrupt all pending states of services in Start(...) method and wait for them to finish. Before invoking OnStop method.
 // Stop method is the must-have method to call before the application is terminated. Its flows are inverted of Setup(...) method.
 // If service B depends on services A, service A will be stopped after service B is stopped.
+// A failing service does not prevent the others from stopping. All failures are reported in a *common.LifecycleError.
 func (bs *Bootstrap) Stop(ctx context.Context) error {
-	sched, ok := bs.schedulers[common.StatusStart]
+	sched, ok := bs.scheduler(common.StatusStart)
 	if ok && sched != nil {
 		sched.Interrupt()
 	}
-	sched, ok = bs.schedulers[common.StatusSetup]
+	sched, ok = bs.scheduler(common.StatusSetup)
 	if !ok || sched == nil {
 		bs.LogS("Setup is not executed. Skip stopping process")
 		return nil
@@ -221,15 +287,34 @@ func (bs *Bootstrap) Stop(ctx context.Context) error {
 	}
 	untag := bs.AddTag("Stop")
 	defer untag()
-	bs.LogS("EXECUTE %s WITH %d SERVICES", common.StatusStop.String(), len(tasks))
-	sched = scheduler.NewScheduler(ctx, bs.Logger.Clone(), tasks, common.StatusStop, bs.numOfConcurrencies)
+	var stopTasks []types.ITask
+	for _, task := range tasks {
+		if sb, ok := task.(*Service); !ok || sb.Status() != common.StatusStop {
+			stopTasks = append(stopTasks, task)
+		}
+	}
+	bs.LogS("EXECUTE %s WITH %d SERVICES", common.StatusStop.String(), len(stopTasks))
+	sched, err = bs.newScheduler(ctx, stopTasks, common.StatusStop, bs.numOfConcurrencies)
+	if err != nil {
+		return err
+	}
 	for _, service := range bs.services {
-		if service.status < common.StatusSetup {
+		if status := service.Status(); status < common.StatusSetup || status == common.StatusStop {
 			sched.SetIgnore(service)
 		}
 	}
+	bs.schedMutex.Lock()
 	bs.schedulers[common.StatusStop] = sched
-	return sched.Run(ctx)
+	bs.schedMutex.Unlock()
+	return bs.runScheduler(ctx, sched, common.StatusStop)
+}
+
+// scheduler returns the last scheduler of the phase.
+func (bs *Bootstrap) scheduler(ss common.ServiceStatus) (*scheduler.Scheduler, bool) {
+	bs.schedMutex.Lock()
+	defer bs.schedMutex.Unlock()
+	sched, ok := bs.schedulers[ss]
+	return sched, ok
 }
 
 // Interrupt method is used to notify all services to stopo their processes.
@@ -239,10 +324,18 @@ func (bs *Bootstrap) Stop(ctx context.Context) error {
 // For example:
 // router are waiting for success of database connection, if interrupt is called, router will stop waiting for database connection and return without setting up.
 // if router are running, it will continue to serve requests until OnStop(...) was called to safely shutdown router.
+// Phases (Init, Setup, Start) which have not begun when interrupt is called are skipped.
 func (bs *Bootstrap) Interrupt(ctx context.Context, reason int) {
+	bs.schedMutex.Lock()
 	bs.errno = reason
-	for k := range bs.schedulers {
-		bs.schedulers[k].Interrupt()
+	bs.interrupted.Store(true)
+	scheds := make([]*scheduler.Scheduler, 0, len(bs.schedulers))
+	for _, sched := range bs.schedulers {
+		scheds = append(scheds, sched)
+	}
+	bs.schedMutex.Unlock()
+	for _, sched := range scheds {
+		sched.Interrupt()
 	}
 	for _, service := range bs.services {
 		if service.OnInterrupt != nil {
@@ -251,51 +344,184 @@ func (bs *Bootstrap) Interrupt(ctx context.Context, reason int) {
 	}
 }
 
-func (bs *Bootstrap) StartBootstrap(ctx context.Context, signals ...os.Signal) {
+// ShutdownOutcome tells how StartBootstrap ended.
+type ShutdownOutcome int
+
+const (
+	// ShutdownClean means all services were stopped within the shutdown timeout.
+	ShutdownClean ShutdownOutcome = iota
+	// ShutdownTimedOut means services were still stopping when the shutdown timeout was exceeded.
+	ShutdownTimedOut
+	// ShutdownForced means another signal was received while services were stopping.
+	ShutdownForced
+)
+
+func (so ShutdownOutcome) String() string {
+	switch so {
+	case ShutdownClean:
+		return "Clean"
+	case ShutdownTimedOut:
+		return "TimedOut"
+	case ShutdownForced:
+		return "Forced"
+	default:
+		return "Unknown"
+	}
+}
+
+// StartBootstrap initializes, sets up and starts all services, then waits until the context is done,
+// one of the signals is received (SIGINT and SIGTERM by default) or services fail to boot.
+// Services are then interrupted and stopped within the ShutdownTimeout of the Config. The context of the
+// shutdown does not derive from cancellation of ctx, so Stop always gets the whole budget.
+// If ForceExitOnSecondSignal is set, another signal received while stopping ends the shutdown immediately.
+//
+// It returns how the shutdown ended, along with the errors of the boot and the Stop process.
+func (bs *Bootstrap) StartBootstrap(ctx context.Context, signals ...os.Signal) (ShutdownOutcome, error) {
 	appCtx, cancelAll := context.WithCancel(ctx)
 	defer cancelAll()
+	chBoot := make(chan error, 1)
 	go func(ctx context.Context) {
-		if err := bs.Init(ctx); err != nil {
-			bs.LogS("Failed to init services: %s", err.Error())
-			return
-		}
-		if err := bs.Setup(ctx); err != nil {
-			bs.LogS("Failed to setup services: %s", err.Error())
-			return
-		}
-		bs.Start(ctx)
+		chBoot <- bs.boot(ctx)
 	}(appCtx)
 	if len(signals) == 0 {
 		signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
 	}
 
-	var quit = make(chan os.Signal, len(signals))
+	// Keep room for a second signal which may force the shutdown
+	var quit = make(chan os.Signal, 2)
 	signal.Notify(quit, signals...)
-	defer close(quit)
-	select {
-	case <-appCtx.Done():
-	case sig := <-quit:
-		bs.errno = int(sig.(syscall.Signal))
+	defer signal.Stop(quit)
+
+	var bootErr error
+	for waiting := true; waiting; {
+		select {
+		case <-appCtx.Done():
+			waiting = false
+		case sig := <-quit:
+			if errno, ok := sig.(syscall.Signal); ok {
+				bs.schedMutex.Lock()
+				bs.errno = int(errno)
+				bs.schedMutex.Unlock()
+			}
+			bs.LogS("Received signal %s, shutting down", sig.String())
+			waiting = false
+		case bootErr = <-chBoot:
+			if bootErr != nil {
+				waiting = false
+			}
+			chBoot = nil
+		}
 	}
 
-	bs.Interrupt(ctx, bs.errno)
-	quitCtx, done := context.WithTimeout(appCtx, 10*time.Second)
+	bs.schedMutex.Lock()
+	errno := bs.errno
+	bs.schedMutex.Unlock()
+	bs.Interrupt(ctx, errno)
+	quitCtx, done := context.WithTimeout(context.WithoutCancel(ctx), bs.shutdownTimeout)
+	defer done()
+	chStop := make(chan error, 1)
 	go func() {
-		defer done()
-		bs.Stop(quitCtx)
+		var interruptedErr error
+		if chBoot != nil {
+			// Interrupted phases return soon, following phases are skipped
+			interruptedErr = <-chBoot
+		}
+		err := bs.Stop(quitCtx)
 		bs.Deinit(quitCtx)
+		chStop <- errors.Join(interruptedErr, err)
 	}()
-	// catching ctx.Done(). timeout of 5 seconds.
-	<-quitCtx.Done()
+
+	var forced <-chan os.Signal
+	if bs.forceExit {
+		forced = quit
+	}
+	select {
+	case err := <-chStop:
+		return ShutdownClean, errors.Join(bootErr, err)
+	case <-quitCtx.Done():
+		bs.LogS("Services are not stopped within %s", bs.shutdownTimeout)
+		return ShutdownTimedOut, errors.Join(bootErr, quitCtx.Err())
+	case sig := <-forced:
+		bs.LogS("Received signal %s again, force exit", sig.String())
+		return ShutdownForced, bootErr
+	}
+}
+
+// boot runs Init, Setup and Start of all services in sequence.
+func (bs *Bootstrap) boot(ctx context.Context) error {
+	if err := bs.Init(ctx); err != nil {
+		bs.LogS("Failed to init services: %s", err.Error())
+		return err
+	}
+	if err := bs.Setup(ctx); err != nil {
+		bs.LogS("Failed to setup services: %s", err.Error())
+		return err
+	}
+	if err := bs.Start(ctx); err != nil {
+		bs.LogS("Failed to start services: %s", err.Error())
+		return err
+	}
+	return nil
 }
 
 func (bs *Bootstrap) execute(ctx context.Context, ss common.ServiceStatus, tasks []types.ITask, numOfConcurrencies int) (err error) {
 	untag := bs.AddTag("execute-" + ss.String())
 	defer untag()
-	bs.LogS("EXECUTE %s WITH %d SERVICES", ss.String(), len(tasks))
-	sched := scheduler.NewScheduler(ctx, bs.Logger.Clone(), tasks, ss, numOfConcurrencies)
+	// The scheduler is registered along with the check, so that Interrupt either skips the phase or interrupts it
+	bs.schedMutex.Lock()
+	if bs.interrupted.Load() {
+		bs.schedMutex.Unlock()
+		bs.LogS("Bootstrap is interrupted. Skip %s", ss.String())
+		return context.Canceled
+	}
+	sched, err := bs.newScheduler(ctx, tasks, ss, numOfConcurrencies)
+	if err != nil {
+		bs.schedMutex.Unlock()
+		return err
+	}
 	bs.schedulers[ss] = sched
-	return sched.Run(ctx)
+	bs.schedMutex.Unlock()
+	bs.LogS("EXECUTE %s WITH %d SERVICES", ss.String(), len(tasks))
+	err = bs.runScheduler(ctx, sched, ss)
+	if err != nil && bs.enableRollback && (ss == common.StatusSetup || ss == common.StatusStart) {
+		return bs.rollback(ctx, sched, err)
+	}
+	return err
+}
+
+// rollback stops the services which finished the phase of the failed scheduler, in reverse order.
+// Services out of the run are ignored so that the Stop order between the others still follows the dependencies.
+func (bs *Bootstrap) rollback(ctx context.Context, failed *scheduler.Scheduler, cause error) error {
+	untag := bs.AddTag("rollback")
+	defer untag()
+	finished, _ := failed.Release()
+	rbErr := &common.RollbackError{Cause: cause}
+	if len(finished) == 0 {
+		return rbErr
+	}
+
+	tasks := make([]types.ITask, 0, len(finished))
+	inRun := make(map[string]bool, len(finished))
+	for i := len(finished) - 1; i >= 0; i-- {
+		tasks = append(tasks, finished[i])
+		inRun[finished[i].Name()] = true
+		rbErr.Stopped = append(rbErr.Stopped, finished[i].Name())
+	}
+	bs.LogS("ROLLBACK %d SERVICES", len(tasks))
+	// The context of the failed phase may be already cancelled, it must not cancel the rollback.
+	rbCtx := context.WithoutCancel(ctx)
+	sched, err := bs.newScheduler(rbCtx, tasks, common.StatusStop, bs.numOfConcurrencies)
+	if err != nil {
+		rbErr.Rollback = err
+		return rbErr
+	}
+	for _, service := range bs.services {
+		if !inRun[service.name] {
+			sched.SetIgnore(service)
+		}
+	}
+	rbErr.Rollback = bs.runScheduler(rbCtx, sched, common.StatusStop)
+	return rbErr
 }
 
 func (bs *Bootstrap) setupNetworkConnection(sb *Service, sCfg ServiceLifeCycle) error {
@@ -303,45 +529,115 @@ func (bs *Bootstrap) setupNetworkConnection(sb *Service, sCfg ServiceLifeCycle)
 	bs.Log("Service %s has %d dependencies", logServiceKey, len(sCfg.Deps))
 
 	for _, service := range sCfg.Deps {
+		optional := false
+		if opt, ok := service.(*optionalDependency); ok {
+			service, optional = opt.dep, true
+		}
+		if iface, ok := service.(*interfaceDependency); ok {
+			sb.slots = append(sb.slots, &depSlot{iface: iface.iface, optional: optional, origin: OriginDeps})
+			continue
+		}
+		if group, ok := service.(*groupDependency); ok {
+			sb.slots = append(sb.slots, &depSlot{group: group.name, origin: OriginGroup})
+			continue
+		}
 		key := utils.DefaultServiceName(service)
 
-		dService, ok := bs.keys[key]
-		if !ok {
+		if dService, _ := bs.lookup(sb.module, key); dService == nil && !optional {
 			if err := bs.Add(service, common.StatusUninitialized, key); err != nil {
 				return err
 			}
-			dService = bs.keys[key]
 		}
-		sb.UpdateDependencies(dService)
+		sb.slots = append(sb.slots, &depSlot{key: key, optional: optional, origin: OriginDeps})
 	}
 
 	bs.Log("Service %s has %d extra dependencies", logServiceKey, len(sCfg.ExtraDeps))
 	for _, cService := range sCfg.ExtraDeps {
-		key := cService.Name
+		key, optional, service := cService.Name, cService.Optional, cService.Service
+		// Markers of dependencies are unwrapped, they must never be registered as services
+		if opt, ok := service.(*optionalDependency); ok {
+			service, optional = opt.dep, true
+		}
+		if group, ok := service.(*groupDependency); ok {
+			sb.slots = append(sb.slots, &depSlot{group: group.name, origin: OriginGroup})
+			continue
+		}
+		iface, isIface := service.(*interfaceDependency)
+		if isIface && key == "" {
+			sb.slots = append(sb.slots, &depSlot{iface: iface.iface, optional: optional, origin: OriginExtraDeps})
+			continue
+		}
 		if key == "" {
-			key = utils.DefaultServiceName(cService.Service)
+			key = utils.DefaultServiceName(service)
 		}
-		dService, ok := bs.keys[key]
-		if !ok || dService == nil {
-			if cService.Instance != nil {
+		if dService, _ := bs.lookup(sb.module, key); dService == nil && !optional {
+			switch {
+			case cService.Instance != nil:
 				if err := bs.Add(cService.Instance, common.StatusUninitialized, key); err != nil {
 					return err
 				}
-			} else {
-				if err := bs.Add(cService.Service, common.StatusUninitialized, key); err != nil {
+			case isIface:
+				return fmt.Errorf("service %s implementing %s required by %s: %w",
+					key, iface.iface.String(), sb.name, common.ErrorServiceNotFound)
+			default:
+				if err := bs.Add(service, common.StatusUninitialized, key); err != nil {
 					return err
 				}
 			}
-			dService = bs.keys[key]
 		}
-		sb.UpdateDependencies(dService)
+		sb.slots = append(sb.slots, &depSlot{key: key, optional: optional, origin: OriginExtraDeps})
 	}
-	sCfg.Deps = nil
-	for _, dep := range sb.following {
-		if d, ok := dep.(*Service); ok {
-			sCfg.Deps = append(sCfg.Deps, d.instance)
+	sb.ServiceLifeCycle = sCfg
+	return nil
+}
+
+// linkServices connects every service with the dependencies it declared, once all services are added.
+// Optional dependencies which are not registered are left nil and no connection is made for them.
+func (bs *Bootstrap) linkServices() error {
+	for _, sb := range bs.services {
+		for _, field := range sb.injects {
+			if err := bs.resolveSlot(sb, field.slot); err != nil {
+				return err
+			}
+			if field.slot.service != nil {
+				sb.linkDependency(field.slot.service, field.slot.origin)
+			}
+		}
+		if len(sb.slots) == 0 {
+			continue
 		}
+		for _, slot := range sb.slots {
+			if slot.group != "" {
+				bs.resolveGroup(sb, slot)
+				continue
+			}
+			if err := bs.resolveSlot(sb, slot); err != nil {
+				return err
+			}
+			if slot.service != nil {
+				sb.linkDependency(slot.service, slot.origin)
+			}
+		}
+		sb.Deps = sb.resolvedDeps()
+	}
+	return nil
+}
+
+// resolveSlot finds the service of a dependency slot of sb. A missing optional dependency leaves the slot unresolved.
+func (bs *Bootstrap) resolveSlot(sb *Service, slot *depSlot) error {
+	if slot.iface != nil {
+		key, err := bs.resolveInterface(sb, slot)
+		if err != nil {
+			return err
+		}
+		slot.key = key
+	}
+	slot.service, slot.key = bs.lookup(sb.module, slot.key)
+	if slot.service == nil && !slot.optional {
+		return fmt.Errorf("%s required by %s: %w", slot.key, sb.name, common.ErrorServiceNotFound)
+	}
+	if slot.service == nil {
+		bs.Log("Optional dependency %s of service %s is not registered", utils.CompactName(slot.name()), utils.CompactName(sb.name))
 	}
-	sb.ServiceLifeCycle = sCfg
 	return nil
 }
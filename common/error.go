@@ -1,12 +1,185 @@
 package common
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
 
 var (
 	ErrorEndOfProcessing = errors.New("end of processing")
 	ErrorServiceNotFound = errors.New("service not found")
 	ErrorServiceRan      = errors.New("service has already run")
+	ErrorServiceExists   = errors.New("service already exists")
 	ErrorServiceNotReady = errors.New("service is not ready")
 	ErrorInvalidLength   = errors.New("invalid length")
 	ErrorInvalidType     = errors.New("invalid type")
+	ErrorDependencyCycle = errors.New("dependency cycle")
+	ErrorTimeout         = errors.New("timeout")
+	ErrorPanic           = errors.New("panic")
+
+	ErrorResourceExceeded = errors.New("resource limit exceeded")
+
+	ErrorInterfaceNotFound  = errors.New("no service implements the interface")
+	ErrorAmbiguousInterface = errors.New("many services implement the interface")
+
+	ErrorInvalidScope = errors.New("invalid scope")
+	ErrorScopeClosed  = errors.New("scope is closed")
 )
+
+// CycleError is returned when services depend on each other in a loop.
+// Path holds the service keys of the cycle, the first key is repeated at the end.
+//
+// Example:
+//
+//	var cycleErr *common.CycleError
+//	if errors.As(err, &cycleErr) {
+//		fmt.Println(cycleErr.Path) // [A B C A]
+//	}
+type CycleError struct {
+	Path []string
+}
+
+func (e *CycleError) Error() string {
+	return ErrorDependencyCycle.Error() + ": " + strings.Join(e.Path, " -> ")
+}
+
+func (e *CycleError) Unwrap() error {
+	return ErrorDependencyCycle
+}
+
+// ServiceError describes the failure of a single service in a lifecycle phase.
+// Skipped holds keys of services which were not run because they depend on the failed service.
+//
+// ServiceError can be used as a target of errors.Is to match a failure by service key and/or phase.
+//
+// Example:
+//
+//	if errors.Is(err, &common.ServiceError{Service: dbKey, Phase: common.StatusSetup}) {
+//		// database failed to setup
+//	}
+type ServiceError struct {
+	Phase   ServiceStatus
+	Service string
+	Err     error
+	Skipped []string
+}
+
+func (e *ServiceError) Error() string {
+	return fmt.Sprintf("service %s failed to %s: %v", e.Service, e.Phase.String(), e.Err)
+}
+
+func (e *ServiceError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ServiceError) Is(target error) bool {
+	t, ok := target.(*ServiceError)
+	if !ok || t.Err != nil {
+		return false
+	}
+	return (t.Service == "" || t.Service == e.Service) &&
+		(t.Phase == StatusUninitialized || t.Phase == e.Phase)
+}
+
+// LifecycleError aggregates every service failure of a lifecycle phase.
+// It unwraps to all of its ServiceError, so errors.Is and errors.As inspect each of them.
+type LifecycleError struct {
+	Phase  ServiceStatus
+	Errors []*ServiceError
+}
+
+func (e *LifecycleError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, se := range e.Errors {
+		msgs = append(msgs, se.Error())
+	}
+	return fmt.Sprintf("%s failed with %d error(s): %s", e.Phase.String(), len(e.Errors), strings.Join(msgs, "; "))
+}
+
+func (e *LifecycleError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, se := range e.Errors {
+		errs = append(errs, se)
+	}
+	return errs
+}
+
+// Find returns the failure of the service with the provided key, or nil if the service did not fail.
+func (e *LifecycleError) Find(key string) *ServiceError {
+	for _, se := range e.Errors {
+		if se.Service == key {
+			return se
+		}
+	}
+	return nil
+}
+
+// Skipped returns keys of all services which were not run because of the failures.
+func (e *LifecycleError) Skipped() []string {
+	var skipped []string
+	for _, se := range e.Errors {
+		skipped = append(skipped, se.Skipped...)
+	}
+	return skipped
+}
+
+// RollbackError is returned when a failed lifecycle phase triggered a rollback.
+// Cause is the original error of the phase, Rollback holds the errors raised while stopping
+// the services listed in Stopped. Rollback is nil if all of them stopped successfully.
+type RollbackError struct {
+	Cause    error
+	Rollback error
+	Stopped  []string
+}
+
+func (e *RollbackError) Error() string {
+	if e.Rollback == nil {
+		return fmt.Sprintf("%v (rolled back %d service(s))", e.Cause, len(e.Stopped))
+	}
+	return fmt.Sprintf("%v; rollback: %v", e.Cause, e.Rollback)
+}
+
+func (e *RollbackError) Unwrap() []error {
+	if e.Rollback == nil {
+		return []error{e.Cause}
+	}
+	return []error{e.Cause, e.Rollback}
+}
+
+// TimeoutError is returned when a service does not finish a lifecycle phase in time.
+type TimeoutError struct {
+	Phase   ServiceStatus
+	Service string
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("service %s did not %s within %s: %v", e.Service, e.Phase.String(), e.Timeout, ErrorTimeout)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return ErrorTimeout
+}
+
+// PanicError is returned when a service panics in a lifecycle phase.
+// Value is the recovered value and Stack is the stack trace of the panicking goroutine.
+type PanicError struct {
+	Phase   ServiceStatus
+	Service string
+	Value   any
+	Stack   []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("service %s panicked to %s: %v", e.Service, e.Phase.String(), e.Value)
+}
+
+// Unwrap returns ErrorPanic and the recovered value if it is an error.
+func (e *PanicError) Unwrap() []error {
+	if err, ok := e.Value.(error); ok {
+		return []error{ErrorPanic, err}
+	}
+	return []error{ErrorPanic}
+}
@@ -0,0 +1,19 @@
+package common
+
+import "context"
+
+type interruptKey struct{}
+
+// WithInterrupt returns a copy of ctx carrying the interruption signal of a scheduler.
+// Unlike cancellation, an interruption does not stop running services, it only notifies them
+// that they should not start anything new (e.g. another retry attempt).
+func WithInterrupt(ctx context.Context, interrupted <-chan struct{}) context.Context {
+	return context.WithValue(ctx, interruptKey{}, interrupted)
+}
+
+// Interrupted returns the interruption signal carried by ctx. It returns nil if ctx has no signal,
+// receiving from it blocks forever.
+func Interrupted(ctx context.Context) <-chan struct{} {
+	interrupted, _ := ctx.Value(interruptKey{}).(<-chan struct{})
+	return interrupted
+}
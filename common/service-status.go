@@ -26,3 +26,7 @@ func (ss ServiceStatus) String() string {
 		return "Unknown"
 	}
 }
+
+func (ss ServiceStatus) MarshalText() ([]byte, error) {
+	return []byte(ss.String()), nil
+}
@@ -1,15 +1,58 @@
 package gobs
 
-import "github.com/xarest/gobs/logger"
+import (
+	"time"
+
+	"github.com/xarest/gobs/logger"
+)
 
 type Config struct {
 	NumOfConcurrencies int
 	Logger             logger.LogFnc
 	EnableLogDetail    bool
+
+	// EnableRollback makes a failed Setup or Start stop the services which succeeded in that phase,
+	// in reverse order. The returned error is then a *common.RollbackError.
+	EnableRollback bool
+
+	// DefaultTimeout bounds every lifecycle phase of services which do not declare their own timeout
+	// in ServiceLifeCycle.Timeouts. Zero means no timeout.
+	DefaultTimeout time.Duration
+
+	// ShutdownTimeout is the time StartBootstrap gives services to stop. Default is DEFAULT_SHUTDOWN_TIMEOUT.
+	ShutdownTimeout time.Duration
+
+	// ForceExitOnSecondSignal makes StartBootstrap return without waiting for services to stop
+	// when a signal is received again during the shutdown.
+	ForceExitOnSecondSignal bool
+
+	// EnableInjection makes the bootstrap populate the fields of services tagged with `gobs:"inject"`
+	// before their Setup. The tagged fields become dependencies of the service.
+	//
+	// Example:
+	//
+	//	type D struct {
+	//		B     *B      `gobs:"inject"`
+	//		C     *C      `gobs:"inject,key=C1"`
+	//		Cache Storage `gobs:"inject,optional"`
+	//	}
+	EnableInjection bool
+
+	// ResourceLimits bounds the total weight of services which may run a phase concurrently for each resource
+	// class declared in ServiceLifeCycle.Resources. Classes without limit are not bounded.
+	//
+	// Example:
+	//
+	//	ResourceLimits: map[string]int{"db": 2} // At most 2 services using the database run concurrently
+	ResourceLimits map[string]int
 }
 
-const DEFAULT_MAX_CONCURRENT = -1
+const (
+	DEFAULT_MAX_CONCURRENT   = -1
+	DEFAULT_SHUTDOWN_TIMEOUT = 10 * time.Second
+)
 
 var DefaultConfig = Config{
 	NumOfConcurrencies: DEFAULT_MAX_CONCURRENT,
+	ShutdownTimeout:    DEFAULT_SHUTDOWN_TIMEOUT,
 }
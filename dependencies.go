@@ -3,21 +3,147 @@ package gobs
 import (
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/xarest/gobs/common"
 )
 
 type Dependencies []IService
 
+// depSlot is a dependency declared by a service, resolved once all services are added.
+type depSlot struct {
+	key      string
+	iface    reflect.Type
+	optional bool
+	group    string
+	origin   EdgeOrigin
+	service  *Service
+	members  []*Service
+}
+
+func (slot *depSlot) name() string {
+	if slot.group != "" {
+		return "group " + slot.group
+	}
+	if slot.key == "" && slot.iface != nil {
+		return slot.iface.String()
+	}
+	return slot.key
+}
+
+type optionalDependency struct {
+	dep IService
+}
+
+// Optional marks a dependency in ServiceLifeCycle.Deps as optional. If no service is registered with its key,
+// the dependency is not created and the service receives nil at its position in the dependencies.
+//
+// Example:
+//
+//	Deps: gobs.Dependencies{new(DB), gobs.Optional(new(Cache))}
+func Optional(dep IService) IService {
+	return &optionalDependency{dep: dep}
+}
+
+type interfaceDependency struct {
+	iface reflect.Type
+}
+
+// Iface declares a dependency on the interface T instead of a concrete type. It is resolved to the single
+// registered service whose instance implements T, or to the service bound to T by Bind.
+//
+// Example:
+//
+//	Deps: gobs.Dependencies{gobs.Iface[Storage]()}
+func Iface[T any]() IService {
+	return &interfaceDependency{iface: reflect.TypeFor[T]()}
+}
+
+// Bind resolves every dependency on the interface T to the service registered with the key.
+// It is required when several services implement T.
+//
+// Example:
+//
+//	gobs.Bind[Storage](bs, "s3")
+func Bind[T any](bs *Bootstrap, key string) {
+	bs.bindings[reflect.TypeFor[T]()] = key
+}
+
+// resolveInterface returns the key of the service implementing the interface of the slot.
+// The dependent service itself is never a candidate.
+func (bs *Bootstrap) resolveInterface(sb *Service, slot *depSlot) (string, error) {
+	if slot.iface.Kind() != reflect.Interface {
+		return "", fmt.Errorf("%s is not an interface: %w", slot.iface.String(), common.ErrorInvalidType)
+	}
+	if key, ok := bs.bindings[slot.iface]; ok {
+		return key, nil
+	}
+	var candidates []*Service
+	for _, candidate := range bs.services {
+		if candidate != sb && candidate.instanceType() != nil && candidate.instanceType().Implements(slot.iface) {
+			candidates = append(candidates, candidate)
+		}
+	}
+	// Implementations of the module of the service, then of its parents, are preferred
+	for ns := sb.module; ns != "" && len(candidates) > 1; ns = parentModule(ns) {
+		var local []*Service
+		for _, candidate := range candidates {
+			if candidate.module == ns {
+				local = append(local, candidate)
+			}
+		}
+		if len(local) == 1 {
+			return local[0].name, nil
+		}
+	}
+	keys := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		keys = append(keys, candidate.name)
+	}
+	switch len(keys) {
+	case 0:
+		if slot.optional {
+			return "", nil
+		}
+		return "", fmt.Errorf("%s required by %s: %w", slot.iface.String(), sb.name, common.ErrorInterfaceNotFound)
+	case 1:
+		return keys[0], nil
+	default:
+		return "", fmt.Errorf("%s required by %s is implemented by %s: %w",
+			slot.iface.String(), sb.name, strings.Join(keys, ", "), common.ErrorAmbiguousInterface)
+	}
+}
+
 func (d Dependencies) Assign(pTargets ...IService) error {
 	for id := 0; id < len(pTargets) && id < len(d); id++ {
 		dep := d[id]
 		dst := pTargets[id]
-		if dst == nil {
+		// A missing optional dependency leaves the target untouched
+		if dst == nil || dep == nil {
 			continue
 		}
 
 		dstType := reflect.TypeOf(dst).Elem()
+		// If the destination is a pointer to a slice, the dependency must be the members of a group
+		if dstType.Kind() == reflect.Slice {
+			members, ok := dep.(Dependencies)
+			if !ok {
+				return fmt.Errorf("require a group for %s but got %T %w", dstType.String(), dep, common.ErrorInvalidType)
+			}
+			elems := reflect.MakeSlice(dstType, 0, len(members))
+			for _, member := range members {
+				memberValue := reflect.ValueOf(member)
+				if !memberValue.Type().AssignableTo(dstType.Elem()) {
+					return fmt.Errorf("require %s but got %s %w",
+						dstType.Elem().String(), memberValue.Type().String(),
+						common.ErrorInvalidType)
+				}
+				elems = reflect.Append(elems, memberValue)
+			}
+			reflect.ValueOf(dst).Elem().Set(elems)
+			continue
+		}
+
 		// If the destination is a pointer to a struct, we can directly assign pointers address to the pointer's value
 		if dstType.Kind() == reflect.Ptr {
 			depType := reflect.TypeOf(dep)
@@ -37,11 +163,18 @@ func (d Dependencies) Assign(pTargets ...IService) error {
 		}
 
 		dstElemType := dstValue.Elem().Type()
+		depValue := reflect.ValueOf(dep)
+		// Plain values, e.g. supplied by Bootstrap.Supply, are copied to the destination
 		if dstElemType.Kind() != reflect.Interface {
-			return fmt.Errorf("destination must be a pointer to an interface: %w", common.ErrorInvalidType)
+			if !depValue.Type().AssignableTo(dstElemType) {
+				return fmt.Errorf("require %s but got %s %w",
+					dstElemType.String(), depValue.Type().String(),
+					common.ErrorInvalidType)
+			}
+			dstValue.Elem().Set(depValue)
+			continue
 		}
 
-		depValue := reflect.ValueOf(dep)
 		if !depValue.Type().Implements(dstElemType) {
 			return fmt.Errorf("dependency does not implement the interface: %w", common.ErrorInvalidType)
 		}
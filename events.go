@@ -0,0 +1,174 @@
+package gobs
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/xarest/gobs/common"
+	"github.com/xarest/gobs/scheduler"
+	"github.com/xarest/gobs/types"
+)
+
+type EventType int
+
+const (
+	// EventServiceAdded is emitted when a service is added to the bootstrap.
+	EventServiceAdded EventType = iota
+	// EventPhaseStarted is emitted when the bootstrap begins a lifecycle phase.
+	EventPhaseStarted
+	// EventPhaseFinished is emitted when the bootstrap ends a lifecycle phase. Err is the error of the phase.
+	EventPhaseFinished
+	// EventServiceReady is emitted when all dependencies of a service finished the phase.
+	EventServiceReady
+	// EventServiceRunning is emitted when a service begins to run the phase.
+	EventServiceRunning
+	// EventServiceSucceeded is emitted when a service finished the phase without error.
+	EventServiceSucceeded
+	// EventServiceFailed is emitted when a service failed the phase. Err is the error of the service.
+	EventServiceFailed
+	// EventServiceWaiting is emitted when a ready service waits for the resource class Resource used by others.
+	EventServiceWaiting
+)
+
+func (et EventType) String() string {
+	switch et {
+	case EventServiceAdded:
+		return "ServiceAdded"
+	case EventPhaseStarted:
+		return "PhaseStarted"
+	case EventPhaseFinished:
+		return "PhaseFinished"
+	case EventServiceReady:
+		return "ServiceReady"
+	case EventServiceRunning:
+		return "ServiceRunning"
+	case EventServiceSucceeded:
+		return "ServiceSucceeded"
+	case EventServiceFailed:
+		return "ServiceFailed"
+	case EventServiceWaiting:
+		return "ServiceWaiting"
+	default:
+		return "Unknown"
+	}
+}
+
+// Event notifies a change in the bootstrap. Service is empty for phase events.
+// StartedAt and EndedAt are both set for finished phases and services, otherwise StartedAt is when the event happened.
+type Event struct {
+	Type      EventType
+	Service   string
+	Phase     common.ServiceStatus
+	StartedAt time.Time
+	EndedAt   time.Time
+	Resource  string
+	Err       error
+}
+
+// eventBus keeps subscribers in the order they subscribed. The slice is copied on (un)subscribe and never
+// modified in place, so emit calls subscribers without copying it nor holding the lock.
+type eventBus struct {
+	mutex       sync.RWMutex
+	nextID      int
+	subscribers []subscriber
+}
+
+type subscriber struct {
+	id int
+	fn func(Event)
+}
+
+// Subscribe registers a function which is called on every event of the bootstrap and returns a function to unsubscribe.
+// Events of services running in async mode are emitted concurrently, so the function must be safe for concurrent use
+// and should return quickly as it blocks the service which emits the event.
+//
+// Example:
+//
+//	unsubscribe := bs.Subscribe(func(e gobs.Event) {
+//		if e.Type == gobs.EventServiceFailed {
+//			metrics.Failures.WithLabelValues(e.Service, e.Phase.String()).Inc()
+//		}
+//	})
+//	defer unsubscribe()
+func (bs *Bootstrap) Subscribe(fn func(Event)) (unsubscribe func()) {
+	bs.events.mutex.Lock()
+	defer bs.events.mutex.Unlock()
+	id := bs.events.nextID
+	bs.events.nextID++
+	bs.events.subscribers = append(slices.Clip(bs.events.subscribers), subscriber{id: id, fn: fn})
+	return func() {
+		bs.events.mutex.Lock()
+		defer bs.events.mutex.Unlock()
+		bs.events.subscribers = slices.DeleteFunc(slices.Clone(bs.events.subscribers), func(s subscriber) bool {
+			return s.id == id
+		})
+	}
+}
+
+func (bs *Bootstrap) emit(event Event) {
+	bs.events.mutex.RLock()
+	subscribers := bs.events.subscribers
+	bs.events.mutex.RUnlock()
+	if len(subscribers) == 0 {
+		return
+	}
+	if event.StartedAt.IsZero() {
+		event.StartedAt = time.Now()
+	}
+	// Subscribers are called without holding the lock, so they are free to (un)subscribe
+	for _, s := range subscribers {
+		s.fn(event)
+	}
+}
+
+// newScheduler creates a scheduler for the phase which forwards the changes of its tasks as events of the bootstrap.
+func (bs *Bootstrap) newScheduler(ctx context.Context, tasks []types.ITask, ss common.ServiceStatus, numOfConcurrencies int) (*scheduler.Scheduler, error) {
+	sched := scheduler.NewScheduler(ctx, bs.Logger.Clone(), tasks, ss, numOfConcurrencies)
+	if err := sched.SetResourceLimits(bs.resourceLimits); err != nil {
+		return nil, err
+	}
+	sched.Observe(bs.profile(ss).observe)
+	sched.Observe(func(te scheduler.TaskEvent) {
+		event := Event{
+			Service:   te.Task.Name(),
+			Phase:     te.Phase,
+			StartedAt: te.Time,
+			Err:       te.Err,
+		}
+		switch te.State {
+		case scheduler.TaskReady:
+			event.Type = EventServiceReady
+		case scheduler.TaskRunning:
+			event.Type = EventServiceRunning
+		case scheduler.TaskSucceeded:
+			event.Type = EventServiceSucceeded
+			event.StartedAt, event.EndedAt = te.StartedAt, te.Time
+		case scheduler.TaskFailed:
+			event.Type = EventServiceFailed
+			event.StartedAt, event.EndedAt = te.StartedAt, te.Time
+		case scheduler.TaskWaiting:
+			event.Type = EventServiceWaiting
+			event.Resource = te.Resource
+		}
+		bs.emit(event)
+	})
+	return sched, nil
+}
+
+// runScheduler runs the scheduler of the phase between EventPhaseStarted and EventPhaseFinished.
+// The scheduler must be the last one created for the phase by newScheduler, so that the run is profiled.
+func (bs *Bootstrap) runScheduler(ctx context.Context, sched *scheduler.Scheduler, ss common.ServiceStatus) error {
+	bs.profileMutex.Lock()
+	pp := bs.profiles[ss]
+	bs.profileMutex.Unlock()
+	startedAt := time.Now()
+	pp.begin(startedAt)
+	bs.emit(Event{Type: EventPhaseStarted, Phase: ss, StartedAt: startedAt})
+	err := sched.Run(ctx)
+	endedAt := time.Now()
+	pp.end(endedAt)
+	bs.emit(Event{Type: EventPhaseFinished, Phase: ss, StartedAt: startedAt, EndedAt: endedAt, Err: err})
+	return err
+}
@@ -0,0 +1,149 @@
+package gobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xarest/gobs/common"
+)
+
+// EdgeOrigin tells where a dependency between two services was declared.
+type EdgeOrigin string
+
+const (
+	OriginDeps      EdgeOrigin = "Deps"
+	OriginExtraDeps EdgeOrigin = "ExtraDeps"
+	OriginInject    EdgeOrigin = "Inject"
+	OriginGroup     EdgeOrigin = "Group"
+)
+
+// GraphNode describes a service in the dependency graph.
+type GraphNode struct {
+	Key    string                        `json:"key"`
+	Type   string                        `json:"type"`
+	Module string                        `json:"module,omitempty"`
+	Async  map[common.ServiceStatus]bool `json:"async"`
+	Status common.ServiceStatus          `json:"status"`
+}
+
+// GraphEdge describes that service From depends on service To.
+type GraphEdge struct {
+	From   string     `json:"from"`
+	To     string     `json:"to"`
+	Origin EdgeOrigin `json:"origin"`
+}
+
+// Graph is the dependency graph of the services of a bootstrap.
+// Nodes are listed in the order services were added, edges in the order dependencies were declared.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// Graph returns the dependency graph of all services. It must be called after Init.
+func (bs *Bootstrap) Graph() (*Graph, error) {
+	if _, ok := bs.scheduler(common.StatusInit); !ok {
+		return nil, errors.New("Init is not executed")
+	}
+	g := &Graph{
+		Nodes: make([]GraphNode, 0, len(bs.services)),
+		Edges: []GraphEdge{},
+	}
+	for _, sb := range bs.services {
+		node := GraphNode{
+			Key:    sb.name,
+			Type:   sb.instanceType().String(),
+			Module: sb.module,
+			Async:  make(map[common.ServiceStatus]bool, common.StatusStop),
+			Status: sb.Status(),
+		}
+		for ss := common.StatusInit; ss <= common.StatusStop; ss++ {
+			node.Async[ss] = sb.IsRunAsync(ss)
+		}
+		g.Nodes = append(g.Nodes, node)
+		for _, dep := range toServices(sb.following) {
+			g.Edges = append(g.Edges, GraphEdge{From: sb.name, To: dep.name, Origin: sb.origins[dep.name]})
+		}
+	}
+	return g, nil
+}
+
+// JSON encodes the graph with a stable layout: nodes and edges keep their order and phases are sorted.
+func (g *Graph) JSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
+
+// modules returns the modules of the nodes in order of appearance, with the nodes of each module.
+func (g *Graph) modules() ([]string, map[string][]GraphNode) {
+	var names []string
+	nodes := make(map[string][]GraphNode)
+	for _, node := range g.Nodes {
+		if _, ok := nodes[node.Module]; !ok {
+			names = append(names, node.Module)
+		}
+		nodes[node.Module] = append(nodes[node.Module], node)
+	}
+	return names, nodes
+}
+
+// DOT renders the graph in the Graphviz DOT language. Edges point from a service to its dependency.
+// Services of a module are drawn in a cluster.
+func (g *Graph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph gobs {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	names, modules := g.modules()
+	for i, module := range names {
+		indent := "  "
+		if module != "" {
+			fmt.Fprintf(&sb, "  subgraph cluster_%d {\n    label=%q;\n", i, module)
+			indent = "    "
+		}
+		for _, node := range modules[module] {
+			fmt.Fprintf(&sb, "%s%q [label=%q, tooltip=%q];\n", indent, node.Key, node.Key+"\n"+node.Status.String(), node.Type)
+		}
+		if module != "" {
+			sb.WriteString("  }\n")
+		}
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&sb, "  %q -> %q [label=%q];\n", edge.From, edge.To, string(edge.Origin))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart. Edges point from a service to its dependency.
+// Services of a module are drawn in a subgraph.
+func (g *Graph) Mermaid() string {
+	ids := make(map[string]string, len(g.Nodes))
+	for i, node := range g.Nodes {
+		ids[node.Key] = fmt.Sprintf("n%d", i)
+	}
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	names, modules := g.modules()
+	for i, module := range names {
+		indent := "  "
+		if module != "" {
+			fmt.Fprintf(&sb, "  subgraph m%d[\"%s\"]\n", i, mermaidEscape(module))
+			indent = "    "
+		}
+		for _, node := range modules[module] {
+			fmt.Fprintf(&sb, "%s%s[\"%s<br/>%s\"]\n", indent, ids[node.Key], mermaidEscape(node.Key), node.Status.String())
+		}
+		if module != "" {
+			sb.WriteString("  end\n")
+		}
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&sb, "  %s -->|%s| %s\n", ids[edge.From], edge.Origin, ids[edge.To])
+	}
+	return sb.String()
+}
+
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
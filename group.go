@@ -0,0 +1,60 @@
+package gobs
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/xarest/gobs/common"
+)
+
+type groupDependency struct {
+	name string
+}
+
+// Group declares a dependency on all services which are members of the group (see ServiceLifeCycle.Groups).
+// Every member is a dependency of the service, which receives the members as Dependencies at the position
+// of the group once they all finished Setup. A group without members is received empty.
+//
+// Example:
+//
+//	Deps: gobs.Dependencies{gobs.Group("http-routes")}
+//
+//	func (s *Router) Setup(ctx context.Context, deps ...gobs.IService) error {
+//		return gobs.Dependencies(deps).Assign(&s.Routes) // Routes []Route
+//	}
+func Group(name string) IService {
+	return &groupDependency{name: name}
+}
+
+// Members returns the members of a group received in the dependencies as a slice of T.
+//
+// Example:
+//
+//	routes, err := gobs.Members[Route](deps[0])
+func Members[T any](group IService) ([]T, error) {
+	members, ok := group.(Dependencies)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a group: %w", group, common.ErrorInvalidType)
+	}
+	res := make([]T, 0, len(members))
+	for _, member := range members {
+		m, ok := member.(T)
+		if !ok {
+			return nil, fmt.Errorf("member %T of the group is not %s: %w", member, reflect.TypeFor[T]().String(), common.ErrorInvalidType)
+		}
+		res = append(res, m)
+	}
+	return res, nil
+}
+
+// resolveGroup links the service with all members of the group of the slot.
+func (bs *Bootstrap) resolveGroup(sb *Service, slot *depSlot) {
+	slot.members = slot.members[:0]
+	for _, member := range bs.services {
+		if member != sb && slices.Contains(member.Groups, slot.group) {
+			slot.members = append(slot.members, member)
+			sb.linkDependency(member, OriginGroup)
+		}
+	}
+}
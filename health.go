@@ -0,0 +1,225 @@
+package gobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/xarest/gobs/common"
+)
+
+// IServiceHealth is implemented by services which can report their own health.
+// Health is only called on services which have been started.
+type IServiceHealth interface {
+	Health(ctx context.Context) error
+}
+
+type HealthStatus int
+
+const (
+	// HealthUnknown means the service has not been started, so it has not been checked.
+	HealthUnknown HealthStatus = iota
+	// HealthUp means the service and all of its dependencies are healthy.
+	HealthUp
+	// HealthDegraded means the service is healthy but some of its dependencies are down.
+	HealthDegraded
+	// HealthDown means the health check of the service failed.
+	HealthDown
+)
+
+func (hs HealthStatus) String() string {
+	switch hs {
+	case HealthUnknown:
+		return "Unknown"
+	case HealthUp:
+		return "Up"
+	case HealthDegraded:
+		return "Degraded"
+	case HealthDown:
+		return "Down"
+	default:
+		return "Invalid"
+	}
+}
+
+func (hs HealthStatus) MarshalText() ([]byte, error) {
+	return []byte(hs.String()), nil
+}
+
+// ServiceHealth is the result of the health check of a service.
+type ServiceHealth struct {
+	Key    string
+	Status HealthStatus
+	// Started is the startup probe: the service finished its Start phase and is not stopped.
+	Started bool
+	// Live is the liveness probe: the health check of the service itself did not fail.
+	Live bool
+	// Ready is the readiness probe: the service is started, live and all of its dependencies are ready.
+	Ready bool
+	// Err is the error returned by the health check.
+	Err error
+	// Unhealthy lists keys of the (transitive) dependencies which are down.
+	Unhealthy []string
+	Duration  time.Duration
+}
+
+// HealthReport is the result of Bootstrap.Health. Services are listed in the order they were added.
+type HealthReport struct {
+	Status    HealthStatus
+	Services  []*ServiceHealth
+	CheckedAt time.Time
+}
+
+// Live reports whether no service failed its health check.
+func (r *HealthReport) Live() bool {
+	for _, sh := range r.Services {
+		if !sh.Live {
+			return false
+		}
+	}
+	return true
+}
+
+// Ready reports whether all services are ready.
+func (r *HealthReport) Ready() bool {
+	for _, sh := range r.Services {
+		if !sh.Ready {
+			return false
+		}
+	}
+	return true
+}
+
+// Started reports whether all services are started.
+func (r *HealthReport) Started() bool {
+	for _, sh := range r.Services {
+		if !sh.Started {
+			return false
+		}
+	}
+	return true
+}
+
+// Find returns the health of the service with the provided key, or nil if it is not in the report.
+func (r *HealthReport) Find(key string) *ServiceHealth {
+	for _, sh := range r.Services {
+		if sh.Key == key {
+			return sh
+		}
+	}
+	return nil
+}
+
+// Health checks all started services concurrently and reports their health.
+// A service which is healthy itself but depends (directly or not) on a service which is down is reported as degraded.
+func (bs *Bootstrap) Health(ctx context.Context) *HealthReport {
+	report := &HealthReport{
+		Services:  make([]*ServiceHealth, len(bs.services)),
+		CheckedAt: time.Now(),
+	}
+	results := make(map[*Service]*ServiceHealth, len(bs.services))
+	var wg sync.WaitGroup
+	for i, sb := range bs.services {
+		sh := &ServiceHealth{Key: sb.name, Live: true}
+		report.Services[i] = sh
+		results[sb] = sh
+		status := sb.Status()
+		if status < common.StatusStart || status == common.StatusStop {
+			continue
+		}
+		sh.Started = true
+		wg.Add(1)
+		go func(sb *Service, sh *ServiceHealth) {
+			defer wg.Done()
+			begin := time.Now()
+			sh.Err = sb.checkHealth(ctx)
+			sh.Duration = time.Since(begin)
+			sh.Live = sh.Err == nil
+		}(sb, sh)
+	}
+	wg.Wait()
+
+	ready := make(map[*Service]bool, len(bs.services))
+	var isReady func(sb *Service) bool
+	isReady = func(sb *Service) bool {
+		if r, ok := ready[sb]; ok {
+			return r
+		}
+		sh := results[sb]
+		r := sh.Started && sh.Live
+		for _, dep := range sb.following {
+			if d, ok := dep.(*Service); ok && !isReady(d) {
+				r = false
+			}
+		}
+		ready[sb] = r
+		return r
+	}
+
+	for _, sb := range bs.services {
+		sh := results[sb]
+		sh.Ready = isReady(sb)
+		switch {
+		case !sh.Started:
+			sh.Status = HealthUnknown
+		case !sh.Live:
+			sh.Status = HealthDown
+		default:
+			sh.Unhealthy = bs.unhealthyDependencies(sb, results)
+			sh.Status = HealthUp
+			if len(sh.Unhealthy) > 0 {
+				sh.Status = HealthDegraded
+			}
+		}
+	}
+	report.Status = report.overall()
+	return report
+}
+
+// overall returns the worst status of all services: Down, then Degraded, then Unknown.
+func (r *HealthReport) overall() HealthStatus {
+	counts := make(map[HealthStatus]int, HealthDown+1)
+	for _, sh := range r.Services {
+		counts[sh.Status]++
+	}
+	for _, hs := range []HealthStatus{HealthDown, HealthDegraded, HealthUnknown} {
+		if counts[hs] > 0 {
+			return hs
+		}
+	}
+	return HealthUp
+}
+
+// unhealthyDependencies walks the dependencies of the service and returns keys of those which are down.
+func (bs *Bootstrap) unhealthyDependencies(sb *Service, results map[*Service]*ServiceHealth) []string {
+	var unhealthy []string
+	visited := map[*Service]bool{sb: true}
+	queue := append([]*Service{}, toServices(sb.following)...)
+	for len(queue) > 0 {
+		dep := queue[0]
+		queue = queue[1:]
+		if visited[dep] {
+			continue
+		}
+		visited[dep] = true
+		if sh := results[dep]; sh != nil && sh.Started && !sh.Live {
+			unhealthy = append(unhealthy, dep.name)
+		}
+		queue = append(queue, toServices(dep.following)...)
+	}
+	return unhealthy
+}
+
+// checkHealth calls Health of the service instance, a panic is reported as a *common.PanicError.
+func (sb *Service) checkHealth(ctx context.Context) (err error) {
+	s, ok := sb.getInstance().(IServiceHealth)
+	if !ok {
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = sb.recovered(sb.Status(), r)
+		}
+	}()
+	return s.Health(ctx)
+}
@@ -0,0 +1,59 @@
+package gobs
+
+import (
+	"time"
+
+	"github.com/xarest/gobs/common"
+)
+
+// PhaseTiming records the last run of a lifecycle phase of a service.
+type PhaseTiming struct {
+	StartedAt time.Time
+	EndedAt   time.Time
+	Err       error
+}
+
+func (pt PhaseTiming) Duration() time.Duration {
+	return pt.EndedAt.Sub(pt.StartedAt)
+}
+
+// ServiceInfo is a snapshot of the state of a service in the bootstrap.
+type ServiceInfo struct {
+	Key          string
+	Status       common.ServiceStatus
+	Dependencies []string
+	Followers    []string
+	Timings      map[common.ServiceStatus]PhaseTiming
+	LastError    error
+}
+
+// Info returns a snapshot of the state of the service.
+func (sb *Service) Info() ServiceInfo {
+	sb.rwStatus.RLock()
+	defer sb.rwStatus.RUnlock()
+	info := ServiceInfo{
+		Key:       sb.name,
+		Status:    sb.status,
+		Timings:   make(map[common.ServiceStatus]PhaseTiming, len(sb.timings)),
+		LastError: sb.lastErr,
+	}
+	for _, dep := range sb.following {
+		info.Dependencies = append(info.Dependencies, dep.Name())
+	}
+	for _, follower := range sb.followers {
+		info.Followers = append(info.Followers, follower.Name())
+	}
+	for ss, timing := range sb.timings {
+		info.Timings[ss] = timing
+	}
+	return info
+}
+
+// Services returns snapshots of all services in the order they were added.
+func (bs *Bootstrap) Services() []ServiceInfo {
+	infos := make([]ServiceInfo, 0, len(bs.services))
+	for _, sb := range bs.services {
+		infos = append(infos, sb.Info())
+	}
+	return infos
+}
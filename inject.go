@@ -0,0 +1,128 @@
+package gobs
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/xarest/gobs/common"
+	"github.com/xarest/gobs/utils"
+)
+
+const injectTag = "gobs"
+
+// injectField is a field of a service instance which is populated with a dependency before Setup.
+type injectField struct {
+	index    []int
+	name     string
+	slot     *depSlot
+	instance IService
+}
+
+// parseInjectFields discovers the fields of the instance tagged with `gobs:"inject"`.
+//
+// The tag accepts the following options, separated by commas:
+//
+//	key=<key>  resolve the dependency by key instead of the type of the field
+//	optional   leave the field untouched if no service is registered for it
+//
+// Fields must be exported. Pointers to structs are resolved by the default key of the struct and created when missing,
+// interfaces are resolved to their single implementation (see Iface), other types require a key.
+func parseInjectFields(s IService) ([]*injectField, error) {
+	v := reflect.ValueOf(s)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, nil
+	}
+	t := v.Elem().Type()
+	var fields []*injectField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup(injectTag)
+		if !ok {
+			continue
+		}
+		opts := strings.Split(tag, ",")
+		if strings.TrimSpace(opts[0]) != "inject" {
+			continue
+		}
+		if !f.IsExported() {
+			return nil, fmt.Errorf("field %s.%s must be exported to be injected: %w", t.String(), f.Name, common.ErrorInvalidType)
+		}
+		field := &injectField{
+			index: f.Index,
+			name:  f.Name,
+			slot:  &depSlot{origin: OriginInject},
+		}
+		for _, opt := range opts[1:] {
+			opt = strings.TrimSpace(opt)
+			switch {
+			case opt == "optional":
+				field.slot.optional = true
+			case strings.HasPrefix(opt, "key="):
+				field.slot.key = strings.TrimPrefix(opt, "key=")
+			default:
+				return nil, fmt.Errorf("unknown option %q of field %s.%s: %w", opt, t.String(), f.Name, common.ErrorInvalidType)
+			}
+		}
+		switch {
+		case f.Type.Kind() == reflect.Ptr && f.Type.Elem().Kind() == reflect.Struct:
+			field.instance = reflect.New(f.Type.Elem()).Interface()
+			if field.slot.key == "" {
+				field.slot.key = utils.DefaultServiceName(field.instance)
+			}
+		case f.Type.Kind() == reflect.Interface:
+			if field.slot.key == "" {
+				field.slot.iface = f.Type
+			}
+		case field.slot.key == "":
+			return nil, fmt.Errorf("field %s.%s of type %s requires a key to be injected: %w",
+				t.String(), f.Name, f.Type.String(), common.ErrorInvalidType)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// addInjectedDependencies adds the missing dependencies of the injected fields of the service.
+func (bs *Bootstrap) addInjectedDependencies(sb *Service) error {
+	for _, field := range sb.injects {
+		if field.slot.iface != nil || field.slot.optional || field.instance == nil {
+			continue
+		}
+		if dep, _ := bs.lookup(sb.module, field.slot.key); dep == nil {
+			if err := bs.Add(field.instance, common.StatusUninitialized, field.slot.key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// inject populates the injected fields of the service instance with their resolved dependencies.
+func (sb *Service) inject(ctx context.Context) error {
+	if len(sb.injects) == 0 {
+		return nil
+	}
+	v := reflect.ValueOf(sb.instance).Elem()
+	for _, field := range sb.injects {
+		if field.slot.service == nil {
+			continue
+		}
+		instance, err := field.slot.service.resolve(ctx)
+		if err != nil {
+			return err
+		}
+		if instance == nil {
+			continue
+		}
+		dst := v.FieldByIndex(field.index)
+		dep := reflect.ValueOf(instance)
+		if !dep.Type().AssignableTo(dst.Type()) {
+			return fmt.Errorf("field %s requires %s but got %s: %w",
+				field.name, dst.Type().String(), dep.Type().String(), common.ErrorInvalidType)
+		}
+		dst.Set(dep)
+	}
+	return nil
+}
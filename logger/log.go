@@ -1,6 +1,9 @@
 package logger
 
-import "fmt"
+import (
+	"fmt"
+	"sync"
+)
 
 type LogFnc func(format string, args ...interface{})
 
@@ -12,6 +15,7 @@ type Logger struct {
 	log         LogFnc
 	isLogDetail bool
 	tag         string
+	mutex       sync.RWMutex
 }
 
 func NewLog(log LogFnc) *Logger {
@@ -25,6 +29,8 @@ func (l *Logger) SetDetail(isEnabled bool) {
 }
 
 func (l *Logger) Clone() *Logger {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
 	return &Logger{
 		log:         l.log,
 		tag:         l.tag,
@@ -32,6 +38,16 @@ func (l *Logger) Clone() *Logger {
 	}
 }
 
+// IsEnabled returns true if messages of LogS are written.
+func (l *Logger) IsEnabled() bool {
+	return l.log != nil
+}
+
+// IsDetail returns true if messages of Log are written.
+func (l *Logger) IsDetail() bool {
+	return l.log != nil && l.isLogDetail
+}
+
 func (l *Logger) Log(format string, args ...interface{}) {
 	if l.isLogDetail {
 		l.LogS(format, args...)
@@ -41,7 +57,10 @@ func (l *Logger) Log(format string, args ...interface{}) {
 func (l *Logger) LogS(format string, args ...interface{}) {
 	if l.log != nil {
 		if l.isLogDetail {
-			args = append([]interface{}{l.tag + ":"}, args...)
+			l.mutex.RLock()
+			tag := l.tag
+			l.mutex.RUnlock()
+			args = append([]interface{}{tag + ":"}, args...)
 			l.log("%s "+format, args...)
 		} else {
 			l.log(format, args...)
@@ -50,17 +69,25 @@ func (l *Logger) LogS(format string, args ...interface{}) {
 }
 
 func (l *Logger) SetTag(tag string) func() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
 	preTag := l.tag
 	l.tag = tag
 	return func() {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
 		l.tag = preTag
 	}
 }
 
 func (l *Logger) AddTag(tag string) func() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
 	preTag := l.tag
 	l.tag = preTag + "/" + tag
 	return func() {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
 		l.tag = preTag
 	}
 }
@@ -0,0 +1,219 @@
+package gobs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/xarest/gobs/common"
+	"github.com/xarest/gobs/types"
+	"github.com/xarest/gobs/utils"
+)
+
+// Module groups services under a namespace. Keys of its services are prefixed with the name of the module
+// and the names of its parents, e.g. "billing::invoice::github.com/acme/app/invoice.Repo".
+//
+// Dependencies of the services of a module are resolved within the module first, then within its parents,
+// then among all services, so modules still share dependencies with the rest of the graph. Missing
+// dependencies are created outside of the module.
+//
+// Example:
+//
+//	bs.AddModule(&gobs.Module{
+//		Name:      "billing",
+//		Services:  []gobs.IService{new(Invoices), gobs.CustomService{Service: new(Cache), Name: "C1"}},
+//		Providers: []any{NewRepo},
+//	})
+type Module struct {
+	Name string
+
+	// Services are added with their default key, or the name of a CustomService.
+	Services []IService
+
+	// Providers are constructors registered like Provide.
+	Providers []any
+
+	// Modules are nested in this module.
+	Modules []*Module
+}
+
+// AddModule adds the services of the module and of its nested modules. It must be called before Init.
+func (bs *Bootstrap) AddModule(m *Module) error {
+	return bs.addModule(m, "")
+}
+
+func (bs *Bootstrap) addModule(m *Module, parent string) error {
+	if m.Name == "" || strings.Contains(m.Name, utils.ModuleSeparator) {
+		return fmt.Errorf("module name %q must not be empty nor contain %q: %w", m.Name, utils.ModuleSeparator, common.ErrorInvalidType)
+	}
+	ns := m.Name
+	if parent != "" {
+		ns = parent + utils.ModuleSeparator + m.Name
+	}
+	untag := bs.AddTag("Module/" + ns)
+	defer untag()
+	for _, s := range m.Services {
+		key := ""
+		if cs, ok := s.(CustomService); ok {
+			s, key = cs.Service, cs.Name
+		}
+		if key == "" {
+			key = utils.DefaultServiceName(s)
+		}
+		key = ns + utils.ModuleSeparator + key
+		if err := bs.Add(s, common.StatusUninitialized, key); err != nil {
+			return err
+		}
+		bs.keys[key].module = ns
+	}
+	for _, ctor := range m.Providers {
+		if err := bs.provide(ctor, ns); err != nil {
+			return err
+		}
+	}
+	bs.LogS("Module %s is added with %d services and %d providers", ns, len(m.Services), len(m.Providers))
+	for _, child := range m.Modules {
+		if err := bs.addModule(child, ns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookup finds the service with the key as seen from the module: keys of the module and of its parents
+// are preferred over global keys. It returns the key of the service found, or the key itself.
+func (bs *Bootstrap) lookup(module, key string) (*Service, string) {
+	for ns := module; ns != ""; ns = parentModule(ns) {
+		if sb, ok := bs.keys[ns+utils.ModuleSeparator+key]; ok {
+			return sb, ns + utils.ModuleSeparator + key
+		}
+	}
+	return bs.keys[key], key
+}
+
+func parentModule(ns string) string {
+	if i := strings.LastIndex(ns, utils.ModuleSeparator); i >= 0 {
+		return ns[:i]
+	}
+	return ""
+}
+
+// inModule tells whether the service belongs to the module or to one of its nested modules.
+func (sb *Service) inModule(ns string) bool {
+	return sb.module == ns || strings.HasPrefix(sb.module, ns+utils.ModuleSeparator)
+}
+
+func (bs *Bootstrap) moduleServices(ns string) ([]*Service, error) {
+	var members []*Service
+	for _, sb := range bs.services {
+		if sb.inModule(ns) {
+			members = append(members, sb)
+		}
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("module %s: %w", ns, common.ErrorServiceNotFound)
+	}
+	return members, nil
+}
+
+// StartModule sets up and starts the services of the module which are not running, e.g. after StopModule,
+// together with the services depending on them which were stopped, so StartModule restarts what StopModule stopped.
+// Other dependencies must have been set up already.
+func (bs *Bootstrap) StartModule(ctx context.Context, name string) error {
+	untag := bs.AddTag("StartModule/" + name)
+	defer untag()
+	members, err := bs.moduleServices(name)
+	if err != nil {
+		return err
+	}
+	var setupTasks []*Service
+	inRun := make(map[*Service]bool, len(members))
+	for _, sb := range bs.withDependents(members) {
+		status := sb.Status()
+		if status == common.StatusStop || (sb.inModule(name) && status < common.StatusSetup) {
+			setupTasks = append(setupTasks, sb)
+			inRun[sb] = true
+		}
+	}
+	for _, sb := range setupTasks {
+		for _, dep := range toServices(sb.following) {
+			if status := dep.Status(); !inRun[dep] && (status < common.StatusSetup || status == common.StatusStop) {
+				return fmt.Errorf("%s required by %s: %w", dep.name, sb.name, common.ErrorServiceNotReady)
+			}
+		}
+	}
+	if err := bs.runSubset(ctx, common.StatusSetup, setupTasks); err != nil {
+		return err
+	}
+	var startTasks []*Service
+	for _, sb := range setupTasks {
+		if sb.Status() == common.StatusSetup {
+			startTasks = append(startTasks, sb)
+		}
+	}
+	return bs.runSubset(ctx, common.StatusStart, startTasks)
+}
+
+// StopModule stops the running services of the module, together with the services depending on them
+// which would not work anymore. Dependents are stopped first.
+func (bs *Bootstrap) StopModule(ctx context.Context, name string) error {
+	untag := bs.AddTag("StopModule/" + name)
+	defer untag()
+	members, err := bs.moduleServices(name)
+	if err != nil {
+		return err
+	}
+	var tasks []*Service
+	for _, sb := range bs.withDependents(members) {
+		if status := sb.Status(); status >= common.StatusSetup && status != common.StatusStop {
+			tasks = append(tasks, sb)
+		}
+	}
+	return bs.runSubset(ctx, common.StatusStop, tasks)
+}
+
+// withDependents returns the services and all services depending on them, in the order they were added.
+func (bs *Bootstrap) withDependents(services []*Service) []*Service {
+	affected := make(map[*Service]bool, len(services))
+	queue := services
+	for len(queue) > 0 {
+		sb := queue[0]
+		queue = queue[1:]
+		if affected[sb] {
+			continue
+		}
+		affected[sb] = true
+		queue = append(queue, toServices(sb.followers)...)
+	}
+	res := make([]*Service, 0, len(affected))
+	for _, sb := range bs.services {
+		if affected[sb] {
+			res = append(res, sb)
+		}
+	}
+	return res
+}
+
+// runSubset runs the phase for some services only. The other services are considered as done.
+func (bs *Bootstrap) runSubset(ctx context.Context, ss common.ServiceStatus, services []*Service) error {
+	if len(services) == 0 {
+		return nil
+	}
+	tasks := make([]types.ITask, 0, len(services))
+	inRun := make(map[*Service]bool, len(services))
+	for _, sb := range services {
+		tasks = append(tasks, sb)
+		inRun[sb] = true
+	}
+	bs.LogS("EXECUTE %s WITH %d SERVICES", ss.String(), len(tasks))
+	sched, err := bs.newScheduler(ctx, tasks, ss, bs.numOfConcurrencies)
+	if err != nil {
+		return err
+	}
+	for _, sb := range bs.services {
+		if !inRun[sb] {
+			sched.SetIgnore(sb)
+		}
+	}
+	return bs.runScheduler(ctx, sched, ss)
+}
@@ -0,0 +1,145 @@
+package gobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/xarest/gobs/common"
+	"github.com/xarest/gobs/logger"
+	"github.com/xarest/gobs/scheduler"
+	"github.com/xarest/gobs/types"
+	"github.com/xarest/gobs/utils"
+)
+
+// PlanStep is a service in a wave of a Plan.
+type PlanStep struct {
+	Key       string
+	Async     bool
+	Priority  int
+	Resources map[string]int
+}
+
+// PlanWave lists the services which become ready at the same time, in the order they are dispatched.
+type PlanWave struct {
+	Steps []PlanStep
+}
+
+// Plan describes how a lifecycle phase would run, without running it.
+type Plan struct {
+	Phase common.ServiceStatus
+	// Sequential is true when services run one by one (NumOfConcurrencies is 0, and always for Init).
+	Sequential bool
+	// Waves are the services layered by dependencies: a service is in the wave following the last of its
+	// dependencies. For Stop, dependents come before their dependencies.
+	Waves []PlanWave
+	// Order is the order in which the scheduler dispatches services, within the concurrency and resource limits,
+	// assuming services finish in the order they are dispatched. In concurrent mode, the actual order depends
+	// on how long services take.
+	Order []string
+}
+
+func (p *Plan) String() string {
+	var sb strings.Builder
+	mode := "concurrent"
+	if p.Sequential {
+		mode = "sequential"
+	}
+	fmt.Fprintf(&sb, "%s (%s)\n", p.Phase.String(), mode)
+	for i, wave := range p.Waves {
+		steps := make([]string, 0, len(wave.Steps))
+		for _, step := range wave.Steps {
+			mode := "sync"
+			if step.Async {
+				mode = "async"
+			}
+			steps = append(steps, fmt.Sprintf("%s (%s)", utils.CompactName(step.Key), mode))
+		}
+		fmt.Fprintf(&sb, "  wave %d: %s\n", i+1, strings.Join(steps, ", "))
+	}
+	return sb.String()
+}
+
+// Plan computes how the scheduler would run the phase for all services: the waves of services which become ready
+// together, whether they run sync or async and the order they are dispatched in, given by a dry run of the
+// scheduler. No method of services is called.
+// It must be called after Init.
+func (bs *Bootstrap) Plan(ss common.ServiceStatus) (*Plan, error) {
+	if _, ok := bs.scheduler(common.StatusInit); !ok {
+		return nil, errors.New("Init is not executed")
+	}
+	if ss < common.StatusInit || ss > common.StatusStop {
+		return nil, fmt.Errorf("cannot plan phase %s: %w", ss.String(), common.ErrorInvalidType)
+	}
+	plan := &Plan{
+		Phase:      ss,
+		Sequential: ss == common.StatusInit || bs.numOfConcurrencies == 0,
+	}
+	byPriority := func(services []*Service) []*Service {
+		sorted := slices.Clone(services)
+		slices.SortStableFunc(sorted, func(a, b *Service) int {
+			return b.Priority(ss) - a.Priority(ss)
+		})
+		return sorted
+	}
+
+	// Kahn layering over the dependencies of the phase
+	remaining := make(map[*Service]int, len(bs.services))
+	for _, sb := range bs.services {
+		remaining[sb] = len(toServices(sb.DependOn(ss)))
+	}
+	var wave []*Service
+	for _, sb := range bs.services {
+		if remaining[sb] == 0 {
+			wave = append(wave, sb)
+		}
+	}
+	planned := 0
+	for len(wave) > 0 {
+		var steps []PlanStep
+		var next []*Service
+		for _, sb := range byPriority(wave) {
+			steps = append(steps, PlanStep{
+				Key:       sb.name,
+				Async:     sb.IsRunAsync(ss),
+				Priority:  sb.Priority(ss),
+				Resources: sb.Resources(ss),
+			})
+			for _, follower := range toServices(sb.Followers(ss)) {
+				remaining[follower]--
+				if remaining[follower] == 0 {
+					next = append(next, follower)
+				}
+			}
+		}
+		planned += len(steps)
+		plan.Waves = append(plan.Waves, PlanWave{Steps: steps})
+		wave = next
+	}
+	if planned != len(bs.services) {
+		return nil, fmt.Errorf("%d services cannot be planned: %w", len(bs.services)-planned, common.ErrorDependencyCycle)
+	}
+
+	tasks := make([]types.ITask, 0, len(bs.services))
+	for _, sb := range bs.services {
+		tasks = append(tasks, sb)
+	}
+	numOfConcurrencies := bs.numOfConcurrencies
+	if plan.Sequential {
+		numOfConcurrencies = 0
+	}
+	sched := scheduler.NewScheduler(context.Background(), logger.NewLog(nil), tasks, ss, numOfConcurrencies)
+	if err := sched.SetResourceLimits(bs.resourceLimits); err != nil {
+		return nil, err
+	}
+	dispatched, err := sched.DryRun(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range dispatched {
+		plan.Order = append(plan.Order, task.Name())
+	}
+	return plan, nil
+}
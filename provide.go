@@ -0,0 +1,244 @@
+package gobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/xarest/gobs/common"
+	"github.com/xarest/gobs/utils"
+)
+
+var (
+	contextType = reflect.TypeFor[context.Context]()
+	errorType   = reflect.TypeFor[error]()
+)
+
+// provider is the service instance of a constructor registered by Provide until the constructor is called.
+type provider struct {
+	ctor      reflect.Value
+	params    []reflect.Type
+	out       reflect.Type
+	hasCtx    bool
+	scope     InstanceScope
+	groups    []string
+	value     IService
+	deps      Dependencies
+	instances []IService
+	mutex     sync.Mutex
+}
+
+type provideOptions struct {
+	name   string
+	scope  InstanceScope
+	groups []string
+}
+
+// ProvideOption customizes the service registered by Provide.
+type ProvideOption func(*provideOptions)
+
+// WithName registers the result of the constructor with the key instead of the default key of its type.
+func WithName(key string) ProvideOption {
+	return func(o *provideOptions) {
+		o.name = key
+	}
+}
+
+// WithScope sets how instances of the constructor are shared. The default scope is ScopeSingleton.
+func WithScope(scope InstanceScope) ProvideOption {
+	return func(o *provideOptions) {
+		o.scope = scope
+	}
+}
+
+// InGroup makes the result of the constructor a member of the groups (see Group).
+func InGroup(groups ...string) ProvideOption {
+	return func(o *provideOptions) {
+		o.groups = append(o.groups, groups...)
+	}
+}
+
+// Provide registers a constructor as a service. Dependencies are derived from the parameter types of the constructor:
+// pointers are resolved by the default key of their type and created when missing, interfaces are resolved like Iface.
+// An optional context.Context may be declared as first parameter. The constructor returns the service, optionally
+// followed by an error.
+//
+// The constructor is called at Setup once all of its dependencies finished Setup. Its result replaces the constructor
+// as the instance of the service, so Start and Stop methods of the result are called as usual.
+// See InstanceScope for constructors which are not singletons.
+//
+// Example:
+//
+//	bs.Provide(func(ctx context.Context, db *DB, cache *Cache) (*Repo, error) {
+//		return NewRepo(db, cache)
+//	})
+func (bs *Bootstrap) Provide(ctor any, opts ...ProvideOption) error {
+	return bs.provide(ctor, "", opts...)
+}
+
+func (bs *Bootstrap) provide(ctor any, module string, opts ...ProvideOption) error {
+	p, err := newProvider(ctor)
+	if err != nil {
+		return err
+	}
+	options := provideOptions{}
+	if p.out.Name() != "" || (p.out.Kind() == reflect.Ptr && p.out.Elem().Name() != "") {
+		options.name = utils.TypeName(p.out)
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.name == "" {
+		return fmt.Errorf("constructor %s returns the unnamed type %s, a name is required: %w",
+			p.ctor.Type().String(), p.out.String(), common.ErrorInvalidType)
+	}
+	p.scope = options.scope
+	p.groups = options.groups
+	key := options.name
+	if module != "" {
+		key = module + utils.ModuleSeparator + key
+	}
+	if bs.keys[key] != nil {
+		return fmt.Errorf("constructor %s provides %s: %w", p.ctor.Type().String(), key, common.ErrorServiceExists)
+	}
+	if err := bs.Add(p, common.StatusUninitialized, key); err != nil {
+		return err
+	}
+	bs.keys[key].module = module
+	return nil
+}
+
+func newProvider(ctor any) (*provider, error) {
+	v := reflect.ValueOf(ctor)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, fmt.Errorf("constructor must be a function, got %T: %w", ctor, common.ErrorInvalidType)
+	}
+	t := v.Type()
+	if t.IsVariadic() {
+		return nil, fmt.Errorf("constructor %s must not be variadic: %w", t.String(), common.ErrorInvalidType)
+	}
+	switch {
+	case t.NumOut() == 1 && t.Out(0) != errorType:
+	case t.NumOut() == 2 && t.Out(0) != errorType && t.Out(1) == errorType:
+	default:
+		return nil, fmt.Errorf("constructor %s must return a service and an optional error: %w", t.String(), common.ErrorInvalidType)
+	}
+	p := &provider{ctor: v, out: t.Out(0)}
+	for i := 0; i < t.NumIn(); i++ {
+		in := t.In(i)
+		if i == 0 && in == contextType {
+			p.hasCtx = true
+			continue
+		}
+		if in.Kind() != reflect.Ptr && in.Kind() != reflect.Interface {
+			return nil, fmt.Errorf("parameter %d of constructor %s must be a pointer or an interface: %w",
+				i, t.String(), common.ErrorInvalidType)
+		}
+		p.params = append(p.params, in)
+	}
+	return p, nil
+}
+
+func (p *provider) Init(ctx context.Context) (*ServiceLifeCycle, error) {
+	deps := make(Dependencies, 0, len(p.params))
+	for _, in := range p.params {
+		if in.Kind() == reflect.Interface {
+			deps = append(deps, &interfaceDependency{iface: in})
+		} else {
+			deps = append(deps, reflect.New(in.Elem()).Interface())
+		}
+	}
+	return &ServiceLifeCycle{Deps: deps, Groups: p.groups}, nil
+}
+
+// Setup calls the constructor of singletons. Transients keep their dependencies to construct an instance
+// for each dependent, scoped services are only constructed by a Scope.
+func (p *provider) Setup(ctx context.Context, deps ...IService) error {
+	switch p.scope {
+	case ScopeTransient:
+		p.deps = deps
+		return nil
+	case ScopeScoped:
+		return nil
+	}
+	value, err := p.construct(ctx, deps)
+	if err != nil {
+		return err
+	}
+	p.value = value
+	return nil
+}
+
+// Start starts the instances constructed for the dependents of a transient.
+func (p *provider) Start(ctx context.Context) error {
+	for _, instance := range p.created() {
+		if s, ok := instance.(IServiceStart); ok {
+			if err := s.Start(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Stop stops the instances constructed for the dependents of a transient in reverse order.
+func (p *provider) Stop(ctx context.Context) error {
+	instances := p.created()
+	var errs []error
+	for i := len(instances) - 1; i >= 0; i-- {
+		if s, ok := instances[i].(IServiceStop); ok {
+			if err := s.Stop(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// create constructs a new instance of a transient for a dependent.
+func (p *provider) create(ctx context.Context) (IService, error) {
+	value, err := p.construct(ctx, p.deps)
+	if err != nil {
+		return nil, err
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.instances = append(p.instances, value)
+	return value, nil
+}
+
+func (p *provider) created() []IService {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]IService(nil), p.instances...)
+}
+
+// construct calls the constructor with the dependencies.
+func (p *provider) construct(ctx context.Context, deps Dependencies) (IService, error) {
+	args := make([]reflect.Value, 0, len(p.params)+1)
+	if p.hasCtx {
+		args = append(args, reflect.ValueOf(ctx))
+	}
+	for i, in := range p.params {
+		if i >= len(deps) || deps[i] == nil {
+			args = append(args, reflect.Zero(in))
+			continue
+		}
+		dep := reflect.ValueOf(deps[i])
+		if !dep.Type().AssignableTo(in) {
+			return nil, fmt.Errorf("constructor %s requires %s but got %s: %w",
+				p.ctor.Type().String(), in.String(), dep.Type().String(), common.ErrorInvalidType)
+		}
+		args = append(args, dep)
+	}
+	out := p.ctor.Call(args)
+	if len(out) == 2 && !out[1].IsNil() {
+		return nil, out[1].Interface().(error)
+	}
+	if (out[0].Kind() == reflect.Ptr || out[0].Kind() == reflect.Interface) && out[0].IsNil() {
+		return nil, fmt.Errorf("constructor %s returned nil: %w", p.ctor.Type().String(), common.ErrorServiceNotReady)
+	}
+	return out[0].Interface(), nil
+}
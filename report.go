@@ -0,0 +1,241 @@
+package gobs
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xarest/gobs/common"
+	"github.com/xarest/gobs/scheduler"
+	"github.com/xarest/gobs/utils"
+)
+
+// DEFAULT_REPORT_TOP is the number of slowest services listed by a PhaseReport.
+const DEFAULT_REPORT_TOP = 5
+
+// ServiceProfile records where the time of a service went during the last run of a phase.
+type ServiceProfile struct {
+	Key string
+	// Waiting is the time spent waiting on dependencies, from the beginning of the phase until the service is ready.
+	Waiting time.Duration
+	// Queued is the time spent between ready and running, e.g. waiting for a worker.
+	Queued time.Duration
+	// Exec is the time spent running the phase method of the service.
+	Exec time.Duration
+
+	ReadyAt   time.Time
+	StartedAt time.Time
+	EndedAt   time.Time
+	Err       error
+}
+
+// PhaseReport profiles the last run of a lifecycle phase.
+type PhaseReport struct {
+	Phase     common.ServiceStatus
+	StartedAt time.Time
+	EndedAt   time.Time
+	// WallTime is the duration of the phase.
+	WallTime time.Duration
+	// TotalWork is the sum of the execution times of all services.
+	TotalWork time.Duration
+	// CriticalPath is the chain of dependencies which finished last, from the first service to run to the last one
+	// to finish. Speeding up services off this path does not shorten the phase.
+	CriticalPath []ServiceProfile
+	// Slowest lists the services with the longest execution time, at most DEFAULT_REPORT_TOP.
+	Slowest []ServiceProfile
+	// Services lists the profiles of all services which ran, in the order they were ready.
+	Services []ServiceProfile
+}
+
+// Top returns the n services with the longest execution time.
+func (pr *PhaseReport) Top(n int) []ServiceProfile {
+	sorted := slices.Clone(pr.Services)
+	slices.SortStableFunc(sorted, func(a, b ServiceProfile) int {
+		return int(b.Exec - a.Exec)
+	})
+	if n < len(sorted) {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// CriticalPathTime is the sum of the execution times along the critical path.
+func (pr *PhaseReport) CriticalPathTime() time.Duration {
+	var total time.Duration
+	for _, p := range pr.CriticalPath {
+		total += p.Exec
+	}
+	return total
+}
+
+// Parallelism is the ratio of the total work over the wall time of the phase.
+func (pr *PhaseReport) Parallelism() float64 {
+	if pr.WallTime <= 0 {
+		return 0
+	}
+	return float64(pr.TotalWork) / float64(pr.WallTime)
+}
+
+func (pr *PhaseReport) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: wall %s, work %s (x%.2f)\n", pr.Phase.String(), pr.WallTime, pr.TotalWork, pr.Parallelism())
+	keys := make([]string, 0, len(pr.CriticalPath))
+	for _, p := range pr.CriticalPath {
+		keys = append(keys, utils.CompactName(p.Key))
+	}
+	fmt.Fprintf(&sb, "  critical path (%s): %s\n", pr.CriticalPathTime(), strings.Join(keys, " -> "))
+	for _, p := range pr.Slowest {
+		fmt.Fprintf(&sb, "  %s: exec %s, queued %s, waiting %s\n", utils.CompactName(p.Key), p.Exec, p.Queued, p.Waiting)
+	}
+	return sb.String()
+}
+
+// Report profiles the last run of each lifecycle phase, in the order of the phases.
+type Report struct {
+	Phases []*PhaseReport
+}
+
+// Phase returns the report of the phase, or nil if the phase has not run.
+func (r *Report) Phase(ss common.ServiceStatus) *PhaseReport {
+	for _, pr := range r.Phases {
+		if pr.Phase == ss {
+			return pr
+		}
+	}
+	return nil
+}
+
+func (r *Report) String() string {
+	var sb strings.Builder
+	for _, pr := range r.Phases {
+		sb.WriteString(pr.String())
+	}
+	return sb.String()
+}
+
+// phaseProfile collects the profiles of services from the events of the scheduler of a phase.
+type phaseProfile struct {
+	mutex     sync.Mutex
+	phase     common.ServiceStatus
+	startedAt time.Time
+	endedAt   time.Time
+	services  map[string]*ServiceProfile
+	order     []string
+}
+
+func newPhaseProfile(ss common.ServiceStatus) *phaseProfile {
+	return &phaseProfile{phase: ss, services: make(map[string]*ServiceProfile)}
+}
+
+func (pp *phaseProfile) observe(te scheduler.TaskEvent) {
+	pp.mutex.Lock()
+	defer pp.mutex.Unlock()
+	key := te.Task.Name()
+	p, ok := pp.services[key]
+	if !ok {
+		p = &ServiceProfile{Key: key}
+		pp.services[key] = p
+		pp.order = append(pp.order, key)
+	}
+	switch te.State {
+	case scheduler.TaskReady:
+		p.ReadyAt = te.Time
+	case scheduler.TaskRunning:
+		p.StartedAt = te.Time
+	case scheduler.TaskSucceeded, scheduler.TaskFailed:
+		p.StartedAt, p.EndedAt, p.Err = te.StartedAt, te.Time, te.Err
+	}
+}
+
+func (pp *phaseProfile) begin(t time.Time) {
+	pp.mutex.Lock()
+	defer pp.mutex.Unlock()
+	pp.startedAt = t
+}
+
+func (pp *phaseProfile) end(t time.Time) {
+	pp.mutex.Lock()
+	defer pp.mutex.Unlock()
+	pp.endedAt = t
+}
+
+func (pp *phaseProfile) report(bs *Bootstrap) *PhaseReport {
+	pp.mutex.Lock()
+	defer pp.mutex.Unlock()
+	pr := &PhaseReport{
+		Phase:     pp.phase,
+		StartedAt: pp.startedAt,
+		EndedAt:   pp.endedAt,
+		WallTime:  pp.endedAt.Sub(pp.startedAt),
+	}
+	profiles := make(map[string]ServiceProfile, len(pp.services))
+	for _, key := range pp.order {
+		p := *pp.services[key]
+		if p.EndedAt.IsZero() {
+			// The service did not finish running
+			continue
+		}
+		p.Waiting = p.ReadyAt.Sub(pp.startedAt)
+		p.Queued = p.StartedAt.Sub(p.ReadyAt)
+		p.Exec = p.EndedAt.Sub(p.StartedAt)
+		pr.TotalWork += p.Exec
+		pr.Services = append(pr.Services, p)
+		profiles[key] = p
+	}
+	pr.Slowest = pr.Top(DEFAULT_REPORT_TOP)
+	pr.CriticalPath = criticalPath(bs, pp.phase, profiles)
+	return pr
+}
+
+// criticalPath walks back from the service which finished last through the dependency which finished last.
+func criticalPath(bs *Bootstrap, ss common.ServiceStatus, profiles map[string]ServiceProfile) []ServiceProfile {
+	var last *ServiceProfile
+	for key := range profiles {
+		p := profiles[key]
+		if last == nil || p.EndedAt.After(last.EndedAt) {
+			last = &p
+		}
+	}
+	var path []ServiceProfile
+	for last != nil {
+		path = append(path, *last)
+		sb := bs.keys[last.Key]
+		last = nil
+		if sb == nil {
+			break
+		}
+		for _, dep := range sb.DependOn(ss) {
+			p, ok := profiles[dep.Name()]
+			if ok && (last == nil || p.EndedAt.After(last.EndedAt)) {
+				last = &p
+			}
+		}
+	}
+	slices.Reverse(path)
+	return path
+}
+
+// Report profiles the last run of each lifecycle phase: the time each service spent waiting on its dependencies,
+// queued and running, the critical path, the wall time against the total work and the slowest services.
+func (bs *Bootstrap) Report() *Report {
+	bs.profileMutex.Lock()
+	defer bs.profileMutex.Unlock()
+	r := &Report{}
+	for ss := common.StatusInit; ss <= common.StatusStop; ss++ {
+		if pp, ok := bs.profiles[ss]; ok {
+			r.Phases = append(r.Phases, pp.report(bs))
+		}
+	}
+	return r
+}
+
+// profile returns a new profile for a run of the phase, which replaces the profile of the previous run.
+func (bs *Bootstrap) profile(ss common.ServiceStatus) *phaseProfile {
+	bs.profileMutex.Lock()
+	defer bs.profileMutex.Unlock()
+	pp := newPhaseProfile(ss)
+	bs.profiles[ss] = pp
+	return pp
+}
@@ -0,0 +1,70 @@
+package gobs
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy describes how a lifecycle phase of a service is retried when it fails.
+//
+// Example:
+//
+//	Retries: map[common.ServiceStatus]*gobs.RetryPolicy{
+//		common.StatusSetup: {
+//			MaxAttempts:    5,
+//			InitialBackoff: 100 * time.Millisecond,
+//			MaxBackoff:     2 * time.Second,
+//			Jitter:         0.2,
+//			Retryable: func(err error) bool {
+//				return !errors.Is(err, ErrInvalidCredentials)
+//			},
+//		},
+//	}
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one. Values lower than 2 disable retrying.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the second attempt.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between two attempts. Zero means no cap.
+	MaxBackoff time.Duration
+
+	// Multiplier grows the delay after each attempt. Default is 2.
+	Multiplier float64
+
+	// Jitter randomizes each delay by up to this fraction of it, in both directions. It must be within [0, 1].
+	Jitter float64
+
+	// Retryable reports whether an error is worth another attempt. All errors are retried if it is nil.
+	Retryable func(err error) bool
+}
+
+// Backoff returns the delay to wait after the failed attempt (starting from 1) before the next one.
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	backoff := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		backoff *= multiplier
+		if p.MaxBackoff > 0 && backoff >= float64(p.MaxBackoff) {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		backoff += backoff * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(backoff)
+}
+
+func (p *RetryPolicy) shouldRetry(attempt int, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+	return p.Retryable == nil || p.Retryable(err)
+}
@@ -0,0 +1,254 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xarest/gobs/common"
+	"github.com/xarest/gobs/logger"
+	"github.com/xarest/gobs/types"
+)
+
+// Task is a node of a graph of tasks run by RunDAG. Run receives the results of the dependencies of the task,
+// keyed by their name.
+type Task[T any] struct {
+	Name      string
+	DependsOn []string
+	// Async tasks run concurrently, the others run one by one.
+	Async bool
+	// Priority orders tasks which are ready at the same time, higher first.
+	Priority int
+	// Resources maps resource classes used by the task to their weight (see Options.ResourceLimits).
+	Resources map[string]int
+	Run       func(ctx context.Context, deps map[string]T) (T, error)
+}
+
+// Result is the outcome of a task. Skipped tasks never ran, because a task failed or the run was cancelled.
+type Result[T any] struct {
+	Name      string
+	Value     T
+	Err       error
+	Skipped   bool
+	StartedAt time.Time
+	EndedAt   time.Time
+}
+
+// Progress notifies a change of state of a task. Done counts the tasks which finished, successfully or not.
+type Progress struct {
+	Task  string
+	State TaskState
+	Done  int
+	Total int
+	Time  time.Time
+	Err   error
+}
+
+// Options configures RunDAG.
+type Options struct {
+	// Concurrency bounds the number of async tasks running at the same time. A negative value means no limit,
+	// zero runs all tasks one by one.
+	Concurrency int
+	// ResourceLimits bounds the total weight of tasks running concurrently for each resource class.
+	ResourceLimits map[string]int
+	// OnProgress is called on every change of state of a task. It may be called concurrently by async tasks.
+	OnProgress func(Progress)
+	Logger     *logger.Logger
+}
+
+// TaskError describes the failure of a task. Skipped holds names of tasks which did not run because of it.
+type TaskError struct {
+	Task    string
+	Err     error
+	Skipped []string
+}
+
+func (e *TaskError) Error() string {
+	return fmt.Sprintf("task %s failed: %v", e.Task, e.Err)
+}
+
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// RunError aggregates the failures of a run of RunDAG: the first failed task and the tasks which failed while
+// running along with it. It unwraps to all of its TaskError.
+type RunError struct {
+	Errors []*TaskError
+}
+
+func (e *RunError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, te := range e.Errors {
+		msgs = append(msgs, te.Error())
+	}
+	return fmt.Sprintf("%d task(s) failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+func (e *RunError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, te := range e.Errors {
+		errs = append(errs, te)
+	}
+	return errs
+}
+
+type dagTask[T any] struct {
+	task      *Task[T]
+	deps      []types.ITask
+	followers []types.ITask
+	run       *dagRun[T]
+}
+
+var (
+	_ types.ITask            = (*dagTask[any])(nil)
+	_ types.IPrioritizedTask = (*dagTask[any])(nil)
+	_ types.IResourceTask    = (*dagTask[any])(nil)
+)
+
+func (t *dagTask[T]) Name() string { return t.task.Name }
+
+func (t *dagTask[T]) IsRunAsync(common.ServiceStatus) bool { return t.task.Async }
+
+func (t *dagTask[T]) DependOn(common.ServiceStatus) []types.ITask { return t.deps }
+
+func (t *dagTask[T]) Followers(common.ServiceStatus) []types.ITask { return t.followers }
+
+func (t *dagTask[T]) Priority(common.ServiceStatus) int { return t.task.Priority }
+
+func (t *dagTask[T]) Resources(common.ServiceStatus) map[string]int { return t.task.Resources }
+
+func (t *dagTask[T]) Run(ctx context.Context, _ common.ServiceStatus) (err error) {
+	deps := make(map[string]T, len(t.task.DependsOn))
+	for _, name := range t.task.DependsOn {
+		deps[name] = t.run.value(name)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("task %s panicked: %v: %w", t.task.Name, r, common.ErrorPanic)
+		}
+	}()
+	value, err := t.task.Run(ctx, deps)
+	if err == nil {
+		t.run.setValue(t.task.Name, value)
+	}
+	return err
+}
+
+type dagRun[T any] struct {
+	mutex   sync.Mutex
+	results map[string]Result[T]
+	done    int
+}
+
+func (r *dagRun[T]) value(name string) T {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.results[name].Value
+}
+
+func (r *dagRun[T]) setValue(name string, value T) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	res := r.results[name]
+	res.Value = value
+	r.results[name] = res
+}
+
+// observe records the state of a task and returns the number of finished tasks.
+func (r *dagRun[T]) observe(te TaskEvent) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	name := te.Task.Name()
+	res := r.results[name]
+	switch te.State {
+	case TaskRunning:
+		res.Skipped = false
+		res.StartedAt = te.Time
+	case TaskSucceeded, TaskFailed:
+		res.Skipped = false
+		res.StartedAt, res.EndedAt, res.Err = te.StartedAt, te.Time, te.Err
+		r.done++
+	}
+	r.results[name] = res
+	return r.done
+}
+
+// RunDAG runs the tasks, each once all of its dependencies succeeded, and returns the result of every task by name.
+// At the first failure, no more task is dispatched and running tasks are awaited. The error is then a *RunError
+// listing the first failed task and the running tasks which failed too, with the tasks skipped because of them.
+// With Concurrency 0, tasks run one by one, so only the first failure is reported. When the context is cancelled, tasks which have
+// not begun are skipped and its error is returned. A graph with unknown dependencies or cycles is not run.
+func RunDAG[T any](ctx context.Context, tasks []Task[T], opts Options) (map[string]Result[T], error) {
+	run := &dagRun[T]{results: make(map[string]Result[T], len(tasks))}
+	nodes := make(map[string]*dagTask[T], len(tasks))
+	iTasks := make([]types.ITask, 0, len(tasks))
+	for i := range tasks {
+		task := &tasks[i]
+		if task.Name == "" || nodes[task.Name] != nil {
+			return nil, fmt.Errorf("task name %q must be unique and not empty: %w", task.Name, common.ErrorInvalidType)
+		}
+		if task.Run == nil {
+			return nil, fmt.Errorf("task %s has no Run function: %w", task.Name, common.ErrorInvalidType)
+		}
+		nodes[task.Name] = &dagTask[T]{task: task, run: run}
+		iTasks = append(iTasks, nodes[task.Name])
+		run.results[task.Name] = Result[T]{Name: task.Name, Skipped: true}
+	}
+	for _, node := range nodes {
+		for _, name := range node.task.DependsOn {
+			dep, ok := nodes[name]
+			if !ok {
+				return nil, fmt.Errorf("%s required by %s: %w", name, node.task.Name, common.ErrorServiceNotFound)
+			}
+			node.deps = append(node.deps, dep)
+			dep.followers = append(dep.followers, node)
+		}
+	}
+	if err := DetectCycle(iTasks, func(task types.ITask) []types.ITask {
+		return task.(*dagTask[T]).deps
+	}); err != nil {
+		return nil, err
+	}
+
+	log := opts.Logger
+	if log == nil {
+		log = logger.NewLog(nil)
+	}
+	log = log.Clone()
+	log.AddTag("Scheduler-DAG")
+	// Tasks of a DAG have no lifecycle phase
+	sched := newScheduler(ctx, log, iTasks, opts.Concurrency)
+	if err := sched.SetResourceLimits(opts.ResourceLimits); err != nil {
+		return nil, err
+	}
+	sched.Observe(func(te TaskEvent) {
+		done := run.observe(te)
+		if opts.OnProgress != nil {
+			opts.OnProgress(Progress{
+				Task:  te.Task.Name(),
+				State: te.State,
+				Done:  done,
+				Total: len(tasks),
+				Time:  te.Time,
+				Err:   te.Err,
+			})
+		}
+	})
+	err := sched.Run(ctx)
+	// Wait for running tasks, so that results are complete
+	sched.Release()
+
+	run.mutex.Lock()
+	defer run.mutex.Unlock()
+	if lErr, ok := err.(*common.LifecycleError); ok {
+		rErr := &RunError{}
+		for _, se := range lErr.Errors {
+			rErr.Errors = append(rErr.Errors, &TaskError{Task: se.Service, Err: se.Err, Skipped: se.Skipped})
+		}
+		return run.results, rErr
+	}
+	return run.results, err
+}
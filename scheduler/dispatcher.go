@@ -0,0 +1,241 @@
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+
+	"github.com/xarest/gobs/logger"
+	"github.com/xarest/gobs/types"
+	"github.com/xarest/gobs/utils"
+)
+
+// readyTask is a task waiting in a readyQueue. seq is the order in which the task became ready.
+type readyTask struct {
+	task     types.ITask
+	priority int
+	seq      int
+}
+
+// readyQueue is a heap of ready tasks: the highest priority first, then the first ready.
+// It implements heap.Interface.
+type readyQueue []readyTask
+
+func (q readyQueue) Len() int { return len(q) }
+
+func (q readyQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q readyQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *readyQueue) Push(x any) { *q = append(*q, x.(readyTask)) }
+
+func (q *readyQueue) Pop() any {
+	old := *q
+	n := len(old) - 1
+	item := old[n]
+	old[n] = readyTask{}
+	*q = old[:n]
+	return item
+}
+
+// dispatcher runs the tasks of a Scheduler in async mode. A single coordinator, the goroutine calling Run,
+// owns the whole state of the run: workers only run a task and send its result back.
+// Ready tasks wait in priority queues: sync tasks run one by one, async tasks run concurrently up to the limit.
+// A task which becomes ready with a higher priority runs before the tasks already waiting. Tasks waiting for
+// resource classes stay queued without holding a slot, so the tasks queued after them may run in the meantime.
+type dispatcher struct {
+	*Scheduler
+	log        *logger.Logger   // shared by running tasks, its tag is never changed
+	limit      int              // maximum number of running async tasks, negative means no limit
+	pending    []int            // number of unfinished dependencies of each task
+	dependents map[string][]int // tasks waiting for each unfinished dependency
+	syncQueue  readyQueue
+	asyncQueue readyQueue
+	seq        int
+	queued     map[string]bool // tasks which became ready, running or not
+	waiting    map[string]bool // tasks which were notified to wait for resources
+	simulated  []taskResult    // tasks started by a dry run, they finish in order
+	syncBusy   bool
+	numOfAsync int
+}
+
+func newDispatcher(r *Scheduler) *dispatcher {
+	numOfTasks := len(r.Tasks)
+	capacity := numOfTasks
+	if r.numOfConcurrencies > 0 && r.numOfConcurrencies+1 < capacity {
+		// At most one sync task runs along with the async ones
+		capacity = r.numOfConcurrencies + 1
+	}
+	d := &dispatcher{
+		Scheduler:  r,
+		log:        r.Logger.Clone(),
+		limit:      r.numOfConcurrencies,
+		pending:    make([]int, numOfTasks),
+		dependents: make(map[string][]int, numOfTasks),
+		queued:     make(map[string]bool, numOfTasks),
+		waiting:    make(map[string]bool),
+	}
+	// Workers never block on sending results, even when nobody receives them after an interrupt
+	r.chRes = make(chan taskResult, capacity)
+	for i, task := range r.Tasks {
+		for _, dep := range task.DependOn(r.status) {
+			depKey := dep.Name()
+			if !r.isFinished[depKey] {
+				d.pending[i]++
+				d.dependents[depKey] = append(d.dependents[depKey], i)
+			}
+		}
+	}
+	return d
+}
+
+// run returns once no task is running nor queued, or when the run is interrupted.
+func (d *dispatcher) run(ctx context.Context) error {
+	d.load(d.Tasks)
+	for {
+		d.dispatch(ctx)
+		if d.inFlight() == 0 {
+			// Every task finished, a task failed and running ones finished, or the remaining tasks wait
+			// for dependencies out of the run.
+			return nil
+		}
+		if d.dryRun {
+			res := d.simulated[0]
+			d.simulated = d.simulated[1:]
+			d.done(res)
+			continue
+		}
+		select {
+		case <-d.ctx.Done():
+			// Results of running tasks are buffered until Release records them.
+			return d.ctx.Err()
+		case res := <-d.chRes:
+			d.done(res)
+		}
+	}
+}
+
+// load queues the tasks of the run which are ready.
+func (d *dispatcher) load(tasks []types.ITask) {
+	for _, task := range tasks {
+		key := task.Name()
+		i, ok := d.index[key]
+		if !ok || d.pending[i] > 0 || d.queued[key] {
+			continue
+		}
+		d.queued[key] = true
+		d.emit(TaskEvent{Task: task, State: TaskReady})
+		queue, mode := &d.syncQueue, "sync"
+		if task.IsRunAsync(d.status) {
+			queue, mode = &d.asyncQueue, "async"
+		}
+		if d.IsDetail() {
+			d.Log("Push service %s to %s queue", utils.CompactName(key), mode)
+		}
+		heap.Push(queue, readyTask{task: task, priority: d.priority(task), seq: d.seq})
+		d.seq++
+	}
+}
+
+// dispatch starts the queued tasks allowed to run. Once a task failed, queued tasks are not started anymore.
+func (d *dispatcher) dispatch(ctx context.Context) {
+	if d.stopped() {
+		return
+	}
+	if !d.syncBusy {
+		if task, ok := d.next(&d.syncQueue); ok {
+			d.syncBusy = true
+			d.start(ctx, task, false)
+		}
+	}
+	for d.limit < 0 || d.numOfAsync < d.limit {
+		task, ok := d.next(&d.asyncQueue)
+		if !ok {
+			return
+		}
+		d.numOfAsync++
+		d.start(ctx, task, true)
+	}
+}
+
+// next pops the first task of the queue whose resources are available and takes them. Tasks waiting for
+// resources are queued again, they are checked at the next dispatch once running tasks released resources.
+func (d *dispatcher) next(queue *readyQueue) (types.ITask, bool) {
+	var blocked []readyTask
+	defer func() {
+		for _, item := range blocked {
+			heap.Push(queue, item)
+		}
+	}()
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(readyTask)
+		class, ok := d.tryAcquire(item.task)
+		if ok {
+			return item.task, true
+		}
+		blocked = append(blocked, item)
+		if key := item.task.Name(); !d.waiting[key] {
+			d.waiting[key] = true
+			d.Log("Service %s waits for resource %s", utils.CompactName(key), class)
+			d.emit(TaskEvent{Task: item.task, State: TaskWaiting, Resource: class})
+		}
+	}
+	return nil, false
+}
+
+func (d *dispatcher) start(ctx context.Context, task types.ITask, async bool) {
+	d.isRunning[task.Name()] = true
+	d.ranList = append(d.ranList, task)
+	if d.dryRun {
+		d.dispatched = append(d.dispatched, task)
+		d.simulated = append(d.simulated, taskResult{task: task, async: async})
+		return
+	}
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		err := d.runTask(ctx, d.log, task)
+		d.chRes <- taskResult{task: task, err: err, async: async}
+	}()
+}
+
+func (d *dispatcher) inFlight() int {
+	if d.syncBusy {
+		return d.numOfAsync + 1
+	}
+	return d.numOfAsync
+}
+
+// done records the result of a task and queues its followers which became ready.
+func (d *dispatcher) done(res taskResult) {
+	if res.async {
+		d.numOfAsync--
+	} else {
+		d.syncBusy = false
+	}
+	task := res.task
+	key := task.Name()
+	d.release(task)
+	d.record(task, res.err)
+	if !d.isFinished[key] {
+		return
+	}
+	for _, i := range d.dependents[key] {
+		d.pending[i]--
+	}
+	delete(d.dependents, key)
+	if d.stopped() {
+		// Stop queuing new tasks, just wait for running ones to finish.
+		return
+	}
+	d.load(task.Followers(d.status))
+}
+
+// stopped tells whether a task failed in a phase which does not go on after failures.
+func (d *dispatcher) stopped() bool {
+	return len(d.failedList) > 0 && !d.keepGoing
+}
@@ -0,0 +1,15 @@
+// Package scheduler runs tasks which depend on each other, each task once all of its dependencies finished.
+//
+// Scheduler is the engine behind the lifecycle phases of gobs.Bootstrap: it runs types.ITask values for a phase,
+// sync tasks one by one and async tasks concurrently, up to a number of concurrent tasks and within the limits of
+// resource classes. It stops dispatching tasks at the first failure and reports every failed task with the tasks
+// skipped because of it.
+//
+// RunDAG exposes the same engine for arbitrary graphs of tasks, e.g. build steps or data migrations, with typed
+// results, progress reporting and cancellation:
+//
+//	results, err := scheduler.RunDAG(ctx, []scheduler.Task[string]{
+//		{Name: "fetch", Async: true, Run: fetch},
+//		{Name: "compile", DependsOn: []string{"fetch"}, Run: compile},
+//	}, scheduler.Options{Concurrency: 4})
+package scheduler
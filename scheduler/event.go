@@ -0,0 +1,100 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/xarest/gobs/common"
+	"github.com/xarest/gobs/logger"
+	"github.com/xarest/gobs/types"
+	"github.com/xarest/gobs/utils"
+)
+
+type TaskState int
+
+const (
+	// TaskReady means all dependencies of the task are finished and the task is queued to run.
+	TaskReady TaskState = iota
+	// TaskRunning means the task has begun to run.
+	TaskRunning
+	// TaskSucceeded means the task finished without error.
+	TaskSucceeded
+	// TaskFailed means the task returned an error.
+	TaskFailed
+	// TaskWaiting means the task is ready but waits for a resource class used by other tasks.
+	TaskWaiting
+)
+
+func (ts TaskState) String() string {
+	switch ts {
+	case TaskReady:
+		return "Ready"
+	case TaskRunning:
+		return "Running"
+	case TaskSucceeded:
+		return "Succeeded"
+	case TaskFailed:
+		return "Failed"
+	case TaskWaiting:
+		return "Waiting"
+	default:
+		return "Unknown"
+	}
+}
+
+// TaskEvent notifies a change of state of a task in a run of the scheduler.
+// StartedAt is set for finished tasks (succeeded or failed), Time is when the event happened.
+// Resource is set for waiting tasks.
+type TaskEvent struct {
+	Task      types.ITask
+	Phase     common.ServiceStatus
+	State     TaskState
+	Time      time.Time
+	StartedAt time.Time
+	Resource  string
+	Err       error
+}
+
+// Observe registers a function which is notified of every change of state of tasks.
+// It must be called before Run. The function may be called concurrently by async workers.
+func (r *Scheduler) Observe(fn func(TaskEvent)) {
+	r.observers = append(r.observers, fn)
+}
+
+func (r *Scheduler) emit(event TaskEvent) {
+	if len(r.observers) == 0 {
+		return
+	}
+	event.Phase = r.status
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	for _, fn := range r.observers {
+		fn(event)
+	}
+}
+
+// runTask runs the task in the phase of the scheduler, logs and notifies observers about its result.
+func (r *Scheduler) runTask(ctx context.Context, log *logger.Logger, task types.ITask) error {
+	if r.dryRun {
+		r.dispatched = append(r.dispatched, task)
+		return nil
+	}
+	logKey := utils.CompactName(task.Name())
+	if log.IsDetail() {
+		log.Log("Service %s is going to be run at %s mode", logKey, r.status.String())
+	}
+	startedAt := time.Now()
+	r.emit(TaskEvent{Task: task, State: TaskRunning, Time: startedAt})
+	if err := task.Run(ctx, r.status); utils.WrapCommonError(err) != nil {
+		log.LogS("Service %s failed to %s: %s", logKey, r.status.String(), err.Error())
+		r.emit(TaskEvent{Task: task, State: TaskFailed, StartedAt: startedAt, Err: err})
+		return err
+	}
+	endedAt := time.Now()
+	if log.IsEnabled() {
+		log.LogS("Service %s %s successfully in %s", logKey, r.status.String(), endedAt.Sub(startedAt))
+	}
+	r.emit(TaskEvent{Task: task, State: TaskSucceeded, Time: endedAt, StartedAt: startedAt})
+	return nil
+}
@@ -0,0 +1,91 @@
+package scheduler
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/xarest/gobs/common"
+	"github.com/xarest/gobs/types"
+)
+
+// resourceClass bounds the total weight of the running tasks which use a resource class.
+// It is only used by the coordinator of a run, so it needs no lock.
+type resourceClass struct {
+	limit int
+	used  int
+}
+
+// SetResourceLimits bounds the total weight of the tasks which may run concurrently for each resource class
+// (see types.IResourceTask). Classes without limit are not bounded. It must be called before Run.
+// It returns an error if a task of the scheduler requires more than the limit of a class, as it could never run.
+func (r *Scheduler) SetResourceLimits(limits map[string]int) error {
+	if err := CheckResources(limits, r.status, r.Tasks...); err != nil {
+		return err
+	}
+	r.resources = make(map[string]*resourceClass, len(limits))
+	for class, limit := range limits {
+		r.resources[class] = &resourceClass{limit: limit}
+	}
+	return nil
+}
+
+// CheckResources returns an error wrapping common.ErrorResourceExceeded if a task requires more than the limit
+// of a resource class in the phase.
+func CheckResources(limits map[string]int, ss common.ServiceStatus, tasks ...types.ITask) error {
+	if len(limits) == 0 {
+		return nil
+	}
+	for _, task := range tasks {
+		t, ok := task.(types.IResourceTask)
+		if !ok {
+			continue
+		}
+		for class, weight := range t.Resources(ss) {
+			if limit, ok := limits[class]; ok && weight > limit {
+				return fmt.Errorf("service %s requires %d of resource %s which is limited to %d: %w",
+					task.Name(), weight, class, limit, common.ErrorResourceExceeded)
+			}
+		}
+	}
+	return nil
+}
+
+// required returns the weights of the limited resource classes required by the task, in sorted order of classes.
+func (r *Scheduler) required(task types.ITask) ([]string, map[string]int) {
+	t, ok := task.(types.IResourceTask)
+	if !ok || len(r.resources) == 0 {
+		return nil, nil
+	}
+	weights := t.Resources(r.status)
+	classes := make([]string, 0, len(weights))
+	for class, weight := range weights {
+		if _, ok := r.resources[class]; ok && weight > 0 {
+			classes = append(classes, class)
+		}
+	}
+	slices.Sort(classes)
+	return classes, weights
+}
+
+// tryAcquire takes the resources required by the task if all of them are available. Otherwise, nothing is taken
+// and the first busy class is returned.
+func (r *Scheduler) tryAcquire(task types.ITask) (string, bool) {
+	classes, weights := r.required(task)
+	for _, class := range classes {
+		if rc := r.resources[class]; rc.limit-rc.used < weights[class] {
+			return class, false
+		}
+	}
+	for _, class := range classes {
+		r.resources[class].used += weights[class]
+	}
+	return "", true
+}
+
+// release gives back the resources taken by tryAcquire for the task.
+func (r *Scheduler) release(task types.ITask) {
+	classes, weights := r.required(task)
+	for _, class := range classes {
+		r.resources[class].used -= weights[class]
+	}
+}
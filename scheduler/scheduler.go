@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"slices"
 	"sync"
 
 	"github.com/xarest/gobs/common"
@@ -10,27 +11,39 @@ import (
 	"github.com/xarest/gobs/utils"
 )
 
+// Scheduler runs tasks of a phase once all of their dependencies finished. A Scheduler runs once.
 type Scheduler struct {
 	*logger.Logger
 	ctx                context.Context
 	cancel             context.CancelFunc
 	status             common.ServiceStatus
+	keepGoing          bool // failed tasks are considered as done, see fail
 	wg                 sync.WaitGroup
-	chReqSync          chan types.ITask
-	chReqAsync         chan types.ITask
-	chRes              chan types.ITask
-	chErr              chan error
+	releaseMutex       sync.Mutex
+	chRes              chan taskResult // results of async runs, see newDispatcher
 	numOfConcurrencies int
+	index              map[string]int
 	isRunning          map[string]bool
 	isFinished         map[string]bool
-	mutexRun           *sync.RWMutex
-	mutexFinished      *sync.RWMutex
 	err                error
 	ranList            []types.ITask
 	finishedList       []types.ITask
+	failedList         []taskResult
+	observers          []func(TaskEvent)
+	resources          map[string]*resourceClass
+	dryRun             bool
+	dispatched         []types.ITask
 	Tasks              []types.ITask
 }
 
+type taskResult struct {
+	task  types.ITask
+	err   error
+	async bool
+}
+
+// NewScheduler creates a scheduler for the tasks in the phase. numOfConcurrencies bounds the number of async tasks
+// running at the same time: a negative value means no limit and zero runs all tasks one by one, in the calling goroutine.
 func NewScheduler(
 	ctx context.Context,
 	log *logger.Logger,
@@ -38,50 +51,63 @@ func NewScheduler(
 	ss common.ServiceStatus,
 	numOfConcurrencies int,
 ) *Scheduler {
+	log.AddTag("Scheduler-" + ss.String())
+	sched := newScheduler(ctx, log, tasks, numOfConcurrencies)
+	sched.status = ss
+	sched.keepGoing = ss == common.StatusStop
+	return sched
+}
+
+// newScheduler creates a scheduler which calls tasks with the zero status and stops dispatching at the first failure.
+func newScheduler(ctx context.Context, log *logger.Logger, tasks []types.ITask, numOfConcurrencies int) *Scheduler {
 	numOfTasks := len(tasks)
-	concurrentLimit := numOfConcurrencies
-	if concurrentLimit < 0 || concurrentLimit > numOfTasks {
-		concurrentLimit = numOfTasks
-	}
 	ctx, cancel := context.WithCancel(ctx)
-	log.AddTag("Scheduler-" + ss.String())
-	sched := &Scheduler{
+	index := make(map[string]int, numOfTasks)
+	for i, task := range tasks {
+		index[task.Name()] = i
+	}
+	return &Scheduler{
 		Logger:             log,
 		ctx:                ctx,
 		cancel:             cancel,
-		status:             ss,
-		chReqSync:          make(chan types.ITask, numOfTasks),
-		chReqAsync:         make(chan types.ITask, concurrentLimit),
-		chRes:              make(chan types.ITask, numOfTasks),
-		chErr:              make(chan error, numOfTasks),
 		numOfConcurrencies: numOfConcurrencies,
+		index:              index,
 		ranList:            make([]types.ITask, 0, numOfTasks),
 		finishedList:       make([]types.ITask, 0, numOfTasks),
 		isRunning:          make(map[string]bool, numOfTasks),
 		isFinished:         make(map[string]bool, numOfTasks),
-		mutexRun:           &sync.RWMutex{},
-		mutexFinished:      &sync.RWMutex{},
 		Tasks:              tasks,
-		err:                nil,
 	}
-	return sched
 }
 
+// SetIgnore considers the task as finished, e.g. a dependency out of the run. It must be called before Run.
 func (r *Scheduler) SetIgnore(t types.ITask) {
-	r.mutexFinished.Lock()
-	defer r.mutexFinished.Unlock()
 	r.isFinished[t.Name()] = true
 }
 
+// Interrupt stops dispatching tasks. Running tasks are notified through common.Interrupted and are not cancelled.
 func (r *Scheduler) Interrupt() {
 	r.cancel()
 }
 
+// Release waits for the run and all of its running tasks to finish, then returns the finished tasks and the run error.
+// Tasks which finished after the run was interrupted are recorded too, so they are part of the finished tasks.
 func (r *Scheduler) Release() ([]types.ITask, error) {
 	r.wg.Wait()
-	return r.finishedList, r.err
+	r.releaseMutex.Lock()
+	defer r.releaseMutex.Unlock()
+	for {
+		select {
+		case res := <-r.chRes:
+			r.record(res.task, res.err)
+		default:
+			return r.finishedList, r.err
+		}
+	}
 }
 
+// Run runs the tasks and returns when all of them finished, a task failed or the run is interrupted.
+// If tasks failed, the error is a *common.LifecycleError. Call Release to wait for running tasks.
 func (r *Scheduler) Run(ctx context.Context) error {
 	untag := r.AddTag("RunSync")
 	r.wg.Add(1)
@@ -89,186 +115,192 @@ func (r *Scheduler) Run(ctx context.Context) error {
 		r.wg.Done()
 		untag()
 	}()
+	// Let tasks know about the interruption without cancelling the context they are running with.
+	ctx = common.WithInterrupt(ctx, r.ctx.Done())
 	if r.numOfConcurrencies == 0 {
 		r.err = r.startSyncRun(ctx, r.Tasks)
+		if r.err == nil || len(r.failedList) > 0 {
+			r.err = r.lifecycleError()
+		}
 		return r.err
 	}
 
-	go r.startProducer()
-	go r.startConsumer(ctx)
-
 	r.Log("Waiting for all tasks to finish")
-	select {
-	case <-r.ctx.Done():
-		r.err = r.ctx.Err()
-	case err := <-r.chErr:
-		r.err = err
+	r.err = newDispatcher(r).run(ctx)
+	if r.err == nil {
+		r.err = r.lifecycleError()
 	}
 	return r.err
 }
 
+// DryRun runs the scheduler without running any task and returns the tasks in the order they would be dispatched.
+// Tasks are assumed to finish in the order they are dispatched: with concurrency, the order of an actual run
+// depends on how long tasks take. A Scheduler runs once, so DryRun replaces Run.
+func (r *Scheduler) DryRun(ctx context.Context) ([]types.ITask, error) {
+	r.dryRun = true
+	if err := r.Run(ctx); err != nil {
+		return nil, err
+	}
+	return r.dispatched, nil
+}
+
+// lifecycleError builds a *common.LifecycleError from all failed tasks of the run.
+// It returns nil if no task failed.
+func (r *Scheduler) lifecycleError() error {
+	if len(r.failedList) == 0 {
+		return nil
+	}
+	lErr := &common.LifecycleError{Phase: r.status}
+	for _, res := range r.failedList {
+		lErr.Errors = append(lErr.Errors, &common.ServiceError{
+			Phase:   r.status,
+			Service: res.task.Name(),
+			Err:     res.err,
+			Skipped: r.skippedBy(res.task),
+		})
+	}
+	return lErr
+}
+
+// skippedBy returns names of tasks which never ran because they (transitively) follow the failed task.
+func (r *Scheduler) skippedBy(failed types.ITask) []string {
+	var skipped []string
+	visited := map[string]bool{failed.Name(): true}
+	queue := failed.Followers(r.status)
+	for len(queue) > 0 {
+		task := queue[0]
+		queue = queue[1:]
+		key := task.Name()
+		if visited[key] {
+			continue
+		}
+		visited[key] = true
+		if _, inRun := r.index[key]; inRun && !r.isRunning[key] {
+			skipped = append(skipped, key)
+		}
+		queue = append(queue, task.Followers(r.status)...)
+	}
+	return skipped
+}
+
+// record adds the task to the finished tasks, or to the failed ones if err is not nil.
+func (r *Scheduler) record(task types.ITask, err error) {
+	if err != nil {
+		r.fail(task, err)
+		return
+	}
+	r.finishedList = append(r.finishedList, task)
+	r.isFinished[task.Name()] = true
+}
+
+// fail records a failed task. In the Stop phase, a failed task is considered as done so that
+// the remaining tasks are still processed and every failure is reported.
+func (r *Scheduler) fail(task types.ITask, err error) {
+	r.failedList = append(r.failedList, taskResult{task: task, err: err})
+	if r.keepGoing {
+		r.isFinished[task.Name()] = true
+	}
+}
+
 func (r *Scheduler) startSyncRun(ctx context.Context, tasks []types.ITask) error {
-	for _, task := range tasks {
+	for _, task := range r.byPriority(tasks) {
 		if r.ctx.Err() != nil {
 			return r.ctx.Err()
 		}
 		key := task.Name()
 		logKey := utils.CompactName(key)
 		if isFinished, ok := r.isFinished[key]; !ok || !isFinished {
-			r.Log("Inspect to run service %s which has %d dependencies", logKey, len(task.DependOn(r.status)))
+			if r.IsDetail() {
+				r.Log("Inspect to run service %s which has %d dependencies", logKey, len(task.DependOn(r.status)))
+			}
 			if err := r.startSyncRun(ctx, task.DependOn(r.status)); err != nil {
 				r.LogS("Service %s failed to run dependencies: %s", logKey, err.Error())
 				return err
 			}
 
-			if err := task.Run(ctx, r.status); utils.WrapCommonError(err) != nil {
-				r.LogS("Service %s failed to %s: %s", logKey, r.status.String(), err.Error())
+			r.isRunning[key] = true
+			r.ranList = append(r.ranList, task)
+			r.emit(TaskEvent{Task: task, State: TaskReady})
+			if err := r.runTask(ctx, r.Logger, task); err != nil {
+				r.fail(task, err)
+				if r.keepGoing {
+					continue
+				}
 				return err
 			}
-			r.LogS("Service %s %s successfully", logKey, r.status.String())
 			r.isFinished[key] = true
 			r.finishedList = append(r.finishedList, task)
-		} else {
+		} else if r.IsDetail() {
 			r.Log("Service %s has been already finished. Skip!", logKey)
 		}
 	}
 	return nil
 }
 
-func (r *Scheduler) startProducer() {
-	log := r.Logger.Clone()
-	untag := log.AddTag("startProducer")
-	defer func() {
-		log.Log("Exit producer")
-		close(r.chReqSync)
-		close(r.chReqAsync)
-		untag()
-	}()
-
-	r.checkAndLoad(r.Tasks)
-
-	utils.WaitOnEvents(r.ctx, func(ctx context.Context, task types.ITask) error {
-		key := task.Name()
-		untag := log.AddTag("response-" + utils.CompactName(key))
-		defer untag()
-		r.finishedList = append(r.finishedList, task)
-		r.mutexFinished.Lock()
-		r.isFinished[key] = true
-		r.mutexFinished.Unlock()
-		if len(r.finishedList) == len(r.Tasks) {
-			return common.ErrorEndOfProcessing
-		}
-		followers := task.Followers(r.status)
-		r.checkAndLoad(followers)
-		return nil
-	}, nil, r.chRes)
-}
-
-func (r *Scheduler) startConsumer(ctx context.Context) {
-	log := r.Logger.Clone()
-	untag := log.AddTag("startConsumer")
-	defer func() {
-		log.Log("Exit consumer")
-		close(r.chRes)
-		close(r.chErr)
-		untag()
-	}()
-	var wgTask sync.WaitGroup
-	wgTask.Add(1)
-	go r.startSyncWorker(ctx, &wgTask)
-	wgTask.Add(1)
-	go r.startAsyncWorker(ctx, &wgTask)
-	wgTask.Wait()
-}
-
-func (r *Scheduler) startSyncWorker(ctx context.Context, wg *sync.WaitGroup) {
-	log := r.Logger.Clone()
-	untag := log.AddTag("startSyncWorker")
-	defer func() {
-		log.Log("Exit sync worker")
-		wg.Done()
-		untag()
-	}()
-	utils.WaitOnEvents(r.ctx, func(_ context.Context, task types.ITask) error {
-		key := task.Name()
-		logKey := utils.CompactName(key)
-		log.Log("Service %s is going to be run at %s mode", logKey, r.status.String())
-		if err := task.Run(ctx, r.status); utils.WrapCommonError(err) != nil {
-			log.LogS("Service %s failed to %s: %s", logKey, r.status.String(), err.Error())
-			r.chErr <- err
-			return err
-		}
-		log.LogS("Service %s %s successfully", logKey, r.status.String())
-		r.chRes <- task
-		return nil
-	}, r.chErr, r.chReqSync)
+// priority returns the priority of the task in the phase of the scheduler.
+func (r *Scheduler) priority(task types.ITask) int {
+	if t, ok := task.(types.IPrioritizedTask); ok {
+		return t.Priority(r.status)
+	}
+	return 0
 }
 
-func (r *Scheduler) startAsyncWorker(ctx context.Context, wg *sync.WaitGroup) {
-	log := r.Logger.Clone()
-	untag := log.AddTag("startAsyncWorker")
-	defer func() {
-		log.Log("Exit async worker")
-		wg.Done()
-		untag()
-	}()
-	utils.WaitOnEvents(r.ctx, func(_ context.Context, task types.ITask) error {
-		wg.Add(1)
-		go func(task types.ITask) {
-			defer wg.Done()
-			key := task.Name()
-			logKey := utils.CompactName(key)
-			log.Log("Service %s is going to be run at %s mode", logKey, r.status.String())
-			if err := task.Run(ctx, r.status); utils.WrapCommonError(err) != nil {
-				log.LogS("Service %s failed to %s: %s", logKey, r.status.String(), err.Error())
-				r.chErr <- err
-				return
-			}
-			log.LogS("Service %s %s successfully", logKey, r.status.String())
-			r.chRes <- task
-		}(task)
-		return nil
-	}, r.chErr, r.chReqAsync)
+// byPriority returns the tasks sorted by descending priority. Tasks with the same priority keep their order.
+func (r *Scheduler) byPriority(tasks []types.ITask) []types.ITask {
+	byPriority := func(a, b types.ITask) int {
+		return r.priority(b) - r.priority(a)
+	}
+	if slices.IsSortedFunc(tasks, byPriority) {
+		return tasks
+	}
+	sorted := slices.Clone(tasks)
+	slices.SortStableFunc(sorted, byPriority)
+	return sorted
 }
 
-func (r *Scheduler) checkAndLoad(tasks []types.ITask) {
-	for _, task := range tasks {
-		if r.checkDependenciesReady(task) {
-			logKey := utils.CompactName(task.Name())
-			r.Log("Service %s is ready to run", logKey)
-			key := task.Name()
-			r.mutexRun.RLock()
-			if isRunning, ok := r.isRunning[key]; !ok || !isRunning {
-				r.mutexRun.RUnlock()
-				r.mutexRun.Lock()
-				r.isRunning[key] = true
-				r.mutexRun.Unlock()
-				r.ranList = append(r.ranList, task)
-				if task.IsRunAsync(r.status) {
-					r.Log("Push service %s to async queue", logKey)
-					r.chReqAsync <- task
-				} else {
-					r.Log("Push service %s to sync queue", logKey)
-					r.chReqSync <- task
+// DetectCycle returns a *common.CycleError describing the first loop found among the tasks, following
+// dependencies given by dependOn. Tasks are visited in order.
+func DetectCycle(tasks []types.ITask, dependOn func(task types.ITask) []types.ITask) error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make(map[types.ITask]int, len(tasks))
+	var path []types.ITask
+	var visit func(task types.ITask) error
+	visit = func(task types.ITask) error {
+		states[task] = visiting
+		path = append(path, task)
+		for _, dep := range dependOn(task) {
+			switch states[dep] {
+			case visiting:
+				var names []string
+				for i := len(path) - 1; i >= 0; i-- {
+					if path[i] == dep {
+						for _, p := range path[i:] {
+							names = append(names, p.Name())
+						}
+						break
+					}
+				}
+				return &common.CycleError{Path: append(names, dep.Name())}
+			case unvisited:
+				if err := visit(dep); err != nil {
+					return err
 				}
-			} else {
-				r.mutexRun.RUnlock()
 			}
 		}
+		path = path[:len(path)-1]
+		states[task] = visited
+		return nil
 	}
-}
-
-func (r *Scheduler) checkDependenciesReady(task types.ITask) bool {
-	r.mutexFinished.RLock()
-	defer r.mutexFinished.RUnlock()
-	logKey := utils.CompactName(task.Name())
-	r.Log("Check if service %s is ready to run", logKey)
-	for _, dep := range task.DependOn(r.status) {
-		depKey := dep.Name()
-		if isFinished, ok := r.isFinished[depKey]; !ok || !isFinished {
-			r.Log("Service %s is not ready to run because service %s is not ready", logKey, utils.CompactName(depKey))
-			return false
+	for _, task := range tasks {
+		if states[task] == unvisited {
+			if err := visit(task); err != nil {
+				return err
+			}
 		}
 	}
-	return true
+	return nil
 }
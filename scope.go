@@ -0,0 +1,218 @@
+package gobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/xarest/gobs/common"
+	"github.com/xarest/gobs/utils"
+)
+
+// InstanceScope defines how instances of a constructor registered by Provide are shared.
+type InstanceScope int
+
+const (
+	// ScopeSingleton constructs a single instance shared by all dependents.
+	ScopeSingleton InstanceScope = iota
+	// ScopeTransient constructs a new instance for each dependent. Instances are started and stopped
+	// together with the transient service.
+	ScopeTransient
+	// ScopeScoped constructs an instance per Scope, lazily at the first resolution in the scope.
+	// Only scoped and transient services may depend on scoped services, such transients are constructed by scopes too.
+	ScopeScoped
+)
+
+func (s InstanceScope) String() string {
+	switch s {
+	case ScopeSingleton:
+		return "Singleton"
+	case ScopeTransient:
+		return "Transient"
+	case ScopeScoped:
+		return "Scoped"
+	default:
+		return "Unknown"
+	}
+}
+
+// scopeOf returns the scope of the service. Services which are not registered by Provide are singletons.
+func (sb *Service) scopeOf() InstanceScope {
+	if p, ok := sb.getInstance().(*provider); ok {
+		return p.scope
+	}
+	return ScopeSingleton
+}
+
+// checkScopes returns an error if a service outlives one of its scoped dependencies. Transient services
+// depending on scoped services, even transitively, are constructed by scopes only, like scoped services.
+func (bs *Bootstrap) checkScopes() error {
+	visited := make(map[*Service]bool, len(bs.services))
+	var inScope func(sb *Service) bool
+	inScope = func(sb *Service) bool {
+		if visited[sb] {
+			return sb.scoped
+		}
+		visited[sb] = true
+		switch sb.scopeOf() {
+		case ScopeScoped:
+			sb.scoped = true
+		case ScopeTransient:
+			for _, dep := range toServices(sb.following) {
+				if inScope(dep) {
+					sb.scoped = true
+					break
+				}
+			}
+		}
+		return sb.scoped
+	}
+	for _, sb := range bs.services {
+		if inScope(sb) {
+			continue
+		}
+		for _, dep := range toServices(sb.following) {
+			if inScope(dep) {
+				return fmt.Errorf("%s service %s depends on %s service %s which is constructed by scopes: %w",
+					sb.scopeOf().String(), sb.name, dep.scopeOf().String(), dep.name, common.ErrorInvalidScope)
+			}
+		}
+	}
+	return nil
+}
+
+// isReady returns true if the service is set up and not stopped.
+func (sb *Service) isReady() bool {
+	status := sb.Status()
+	return status >= common.StatusSetup && status != common.StatusStop
+}
+
+// Scope is a child of the bootstrap, e.g. for an HTTP request or a job. Scoped services are constructed once
+// per scope when they are resolved, transient services are constructed at each resolution and singletons are
+// shared with the bootstrap. Close disposes instances constructed by the scope.
+//
+// Example:
+//
+//	scope := bs.NewScope()
+//	defer scope.Close(ctx)
+//	tx, err := gobs.Resolve[*Tx](ctx, scope)
+type Scope struct {
+	bs        *Bootstrap
+	mutex     sync.Mutex
+	instances map[*Service]IService
+	created   []IService
+	closed    bool
+}
+
+// NewScope creates a child scope of the bootstrap. Setup of the bootstrap must be done before resolving services.
+func (bs *Bootstrap) NewScope() *Scope {
+	return &Scope{
+		bs:        bs,
+		instances: make(map[*Service]IService),
+	}
+}
+
+// Resolve returns the instance of the service registered with the key in the scope.
+func (s *Scope) Resolve(ctx context.Context, key string) (IService, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil, common.ErrorScopeClosed
+	}
+	sb, ok := s.bs.keys[key]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", key, common.ErrorServiceNotFound)
+	}
+	return s.resolve(ctx, sb)
+}
+
+// Resolve returns the instance of type T in the scope. T is resolved by its default key,
+// interfaces bound by Bind are resolved to the bound service.
+func Resolve[T any](ctx context.Context, s *Scope) (T, error) {
+	var zero T
+	t := reflect.TypeFor[T]()
+	key, ok := s.bs.bindings[t]
+	if !ok {
+		key = utils.TypeName(t)
+	}
+	instance, err := s.Resolve(ctx, key)
+	if err != nil {
+		return zero, err
+	}
+	res, ok := instance.(T)
+	if !ok {
+		return zero, fmt.Errorf("%s is %T, not %s: %w", key, instance, t.String(), common.ErrorInvalidType)
+	}
+	return res, nil
+}
+
+func (s *Scope) resolve(ctx context.Context, sb *Service) (IService, error) {
+	p, ok := sb.getInstance().(*provider)
+	if !ok || p.scope == ScopeSingleton {
+		if instance := sb.value(); instance != nil && (sb.supplied || sb.isReady()) {
+			return instance, nil
+		}
+		return nil, fmt.Errorf("%s: %w", sb.name, common.ErrorServiceNotReady)
+	}
+	if instance, ok := s.instances[sb]; ok {
+		return instance, nil
+	}
+	deps := make(Dependencies, 0, len(sb.slots))
+	for _, slot := range sb.slots {
+		if slot.group != "" {
+			members := make(Dependencies, 0, len(slot.members))
+			for _, member := range slot.members {
+				dep, err := s.resolve(ctx, member)
+				if err != nil {
+					return nil, err
+				}
+				members = append(members, dep)
+			}
+			deps = append(deps, members)
+			continue
+		}
+		if slot.service == nil {
+			deps = append(deps, nil)
+			continue
+		}
+		dep, err := s.resolve(ctx, slot.service)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, dep)
+	}
+	instance, err := p.construct(ctx, deps)
+	if err != nil {
+		return nil, err
+	}
+	s.bs.Log("Scope constructed %s service %s", p.scope.String(), utils.CompactName(sb.name))
+	s.created = append(s.created, instance)
+	if p.scope == ScopeScoped {
+		s.instances[sb] = instance
+	}
+	return instance, nil
+}
+
+// Close stops the instances constructed by the scope in reverse order of construction, so dependents are
+// stopped before their dependencies. The scope cannot resolve services anymore.
+func (s *Scope) Close(ctx context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	var errs []error
+	for i := len(s.created) - 1; i >= 0; i-- {
+		if stopper, ok := s.created[i].(IServiceStop); ok {
+			if err := stopper.Stop(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	s.created = nil
+	s.instances = nil
+	return errors.Join(errs...)
+}
@@ -2,7 +2,10 @@ package gobs
 
 import (
 	"context"
+	"reflect"
+	"runtime/debug"
 	"sync"
+	"time"
 
 	"github.com/xarest/gobs/common"
 	"github.com/xarest/gobs/logger"
@@ -83,12 +86,53 @@ type ServiceLifeCycle struct {
 	// AsyncMode is a map of service status and boolean value. If the value is true, the service instance will be run in parallel goroutine context.
 	// Otherwise, the service instance will be run in sequential context.
 	AsyncMode map[common.ServiceStatus]bool
+
+	// Timeouts bounds how long each lifecycle phase of the service may take. When a phase is not listed,
+	// the DefaultTimeout of the bootstrap Config is applied. Once the timeout is exceeded, the context passed
+	// to the phase method is cancelled and the phase fails with a *common.TimeoutError, even if the method
+	// has not returned yet.
+	//
+	// Example:
+	//
+	// 	Timeouts: map[common.ServiceStatus]time.Duration{
+	// 		common.StatusSetup: 5 * time.Second, // Give up dialing the database after 5 seconds
+	// 	}
+	Timeouts map[common.ServiceStatus]time.Duration
+
+	// Retries declares how each lifecycle phase is retried when it fails. Each attempt is bounded by the timeout
+	// of the phase. Retrying stops when the bootstrap is interrupted, the last error is then returned.
+	Retries map[common.ServiceStatus]*RetryPolicy
+
+	// Priority orders the service among services which are ready to run a phase at the same time: services with
+	// a higher priority are dispatched first, e.g. to start the metrics exporter before a slow cache warmer.
+	// Dependencies always run first, whatever their priority. Without concurrency (NumOfConcurrencies is 0),
+	// services run in order of priority, each right after its dependencies. The default priority is 0.
+	Priority int
+
+	// Priorities overrides Priority for some lifecycle phases.
+	Priorities map[common.ServiceStatus]int
+
+	// Resources maps the resource classes used by the service to their weight, e.g. {"db": 1}. The total weight
+	// of services running a phase concurrently is bounded for each class by Config.ResourceLimits.
+	Resources map[string]int
+
+	// PhaseResources overrides Resources for some lifecycle phases, e.g. {common.StatusStart: nil} for a service
+	// which only uses the database to set up.
+	PhaseResources map[common.ServiceStatus]map[string]int
+
+	// Groups lists the groups which the service is a member of. Services depending on a group
+	// depend on all of its members (see Group).
+	Groups []string
 }
 
 type CustomService struct {
 	Service  IService
 	Name     string
 	Instance IService
+
+	// Optional dependencies are not created when no service is registered with the key.
+	// The service then receives nil at its position in the dependencies.
+	Optional bool
 }
 
 type Service struct {
@@ -98,11 +142,26 @@ type Service struct {
 	followers []types.ITask
 	instance  IService
 	name      string
+	module    string
+	supplied  bool
+	scoped    bool // constructed by scopes only, see checkScopes
 	status    common.ServiceStatus
 	mutex     map[common.ServiceStatus]*sync.Mutex
+	rwStatus  sync.RWMutex
+	timings   map[common.ServiceStatus]PhaseTiming
+	origins   map[string]EdgeOrigin
+	slots     []*depSlot
+	injects   []*injectField
+	lastErr   error
+
+	defaultTimeout time.Duration
 }
 
-var _ types.ITask = (*Service)(nil)
+var (
+	_ types.ITask            = (*Service)(nil)
+	_ types.IPrioritizedTask = (*Service)(nil)
+	_ types.IResourceTask    = (*Service)(nil)
+)
 
 func (sb *Service) Name() string {
 	return sb.name
@@ -117,6 +176,8 @@ func NewService(s any, name string, status common.ServiceStatus, log *logger.Log
 		instance: s,
 		name:     name,
 		status:   status,
+		timings:  make(map[common.ServiceStatus]PhaseTiming, common.StatusStop+1),
+		origins:  make(map[string]EdgeOrigin),
 		mutex: map[common.ServiceStatus]*sync.Mutex{
 			common.StatusUninitialized: {},
 			common.StatusInit:          {},
@@ -149,15 +210,184 @@ func (sb *Service) Run(ctx context.Context, ss common.ServiceStatus) (err error)
 		mutex.Lock()
 		defer mutex.Unlock()
 	}
+	begin := time.Now()
+	err = sb.runWithRetry(ctx, ss)
+	sb.rwStatus.Lock()
+	defer sb.rwStatus.Unlock()
+	sb.timings[ss] = PhaseTiming{StartedAt: begin, EndedAt: time.Now(), Err: err}
+	if err != nil {
+		sb.lastErr = err
+		return err
+	}
+	sb.status = ss
+	return nil
+}
+
+// Status returns the last lifecycle phase which the service finished successfully.
+func (sb *Service) Status() common.ServiceStatus {
+	sb.rwStatus.RLock()
+	defer sb.rwStatus.RUnlock()
+	return sb.status
+}
+
+// runWithRetry runs the lifecycle phase until it succeeds or the retry policy of the phase gives up.
+func (sb *Service) runWithRetry(ctx context.Context, ss common.ServiceStatus) error {
+	policy := sb.Retries[ss]
+	logKey := utils.CompactName(sb.name)
+	for attempt := 1; ; attempt++ {
+		err := sb.runWithTimeout(ctx, ss)
+		if err == nil || !policy.shouldRetry(attempt, err) {
+			return err
+		}
+		backoff := policy.Backoff(attempt)
+		sb.LogS("Service %s failed to %s (attempt %d/%d): %s. Retry in %s",
+			logKey, ss.String(), attempt, policy.MaxAttempts, err.Error(), backoff)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-common.Interrupted(ctx):
+			timer.Stop()
+			sb.LogS("Service %s stops retrying to %s as it is interrupted", logKey, ss.String())
+			return err
+		}
+	}
+}
+
+// timeout returns the timeout of the lifecycle phase. Zero means no timeout.
+func (sb *Service) timeout(ss common.ServiceStatus) time.Duration {
+	if timeout, ok := sb.Timeouts[ss]; ok {
+		return timeout
+	}
+	return sb.defaultTimeout
+}
+
+// phaseParentKey holds the context of the bootstrap in the context of a phase run with a timeout.
+type phaseParentKey struct{}
+
+// runWithTimeout runs the lifecycle phase and returns a *common.TimeoutError as soon as the timeout is exceeded.
+// The context passed to the phase method is cancelled on timeout and once the phase returns, so work outliving
+// the phase must not use it. StartServer does not have to care: its server runs with the parent context once ready.
+// If the parent context is cancelled, the phase is waited for as without timeout.
+func (sb *Service) runWithTimeout(ctx context.Context, ss common.ServiceStatus) error {
+	timeout := sb.timeout(ss)
+	if timeout <= 0 {
+		return sb.runSafe(ctx, ss)
+	}
+	timeoutErr := &common.TimeoutError{Phase: ss, Service: sb.name, Timeout: timeout}
+	phaseCtx, cancel := context.WithCancelCause(context.WithValue(ctx, phaseParentKey{}, ctx))
+	defer cancel(nil)
+	timer := time.AfterFunc(timeout, func() {
+		cancel(timeoutErr)
+	})
+	defer timer.Stop()
+
+	chErr := make(chan error, 1)
+	go func() {
+		chErr <- sb.runSafe(phaseCtx, ss)
+	}()
+	select {
+	case err := <-chErr:
+		if err != nil && context.Cause(phaseCtx) == timeoutErr {
+			return timeoutErr
+		}
+		return err
+	case <-phaseCtx.Done():
+		if context.Cause(phaseCtx) != timeoutErr {
+			return <-chErr
+		}
+		sb.LogS("Service %s did not %s within %s", utils.CompactName(sb.name), ss.String(), timeout)
+		return timeoutErr
+	}
+}
+
+// runSafe runs the lifecycle phase and converts a panic of the service into a *common.PanicError.
+func (sb *Service) runSafe(ctx context.Context, ss common.ServiceStatus) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = sb.recovered(ss, r)
+		}
+	}()
+	return sb.runPhase(ctx, ss)
+}
+
+func (sb *Service) recovered(ss common.ServiceStatus, r any) error {
+	err := &common.PanicError{Phase: ss, Service: sb.name, Value: r, Stack: debug.Stack()}
+	sb.LogS("Service %s panicked to %s: %v\n%s", utils.CompactName(sb.name), ss.String(), r, err.Stack)
+	return err
+}
+
+// startServer launches StartServer in its own goroutine and waits until the server reports it is ready,
+// returns or panics. Errors and panics happening after the server is ready are only logged.
+func (sb *Service) startServer(ctx context.Context, s IServiceStartServer) error {
+	logKey := utils.CompactName(sb.name)
+	chErr := make(chan error, 1)
+	var once sync.Once
+	report := func(err error) (reported bool) {
+		once.Do(func() {
+			chErr <- err
+			reported = true
+		})
+		return reported
+	}
+	// The context of a phase with timeout is cancelled once the phase returns, the server must outlive it.
+	// Until the server is ready, it is still cancelled along with the phase.
+	serverCtx := ctx
+	if parent, ok := ctx.Value(phaseParentKey{}).(context.Context); ok {
+		var cancel context.CancelCauseFunc
+		serverCtx, cancel = context.WithCancelCause(parent)
+		stop := context.AfterFunc(ctx, func() {
+			cancel(context.Cause(ctx))
+		})
+		defer stop()
+	}
+	go func(ctx context.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				if err := sb.recovered(common.StatusStart, r); !report(err) {
+					sb.LogS("Service %s panicked after started", logKey)
+				}
+			}
+		}()
+		err := s.StartServer(ctx, func(e error) {
+			report(e)
+		})
+		if !report(err) && err != nil {
+			sb.LogS("Service %s stopped serving: %s", logKey, err.Error())
+		}
+	}(serverCtx)
+	return <-chErr
+}
+
+// runPhase calls the method of the service instance which corresponds to the lifecycle phase.
+func (sb *Service) runPhase(ctx context.Context, ss common.ServiceStatus) (err error) {
 	logKey := utils.CompactName(sb.name)
+	if sb.supplied {
+		sb.Log("Value %s is supplied, skip %s", logKey, ss.String())
+		return nil
+	}
 	switch ss {
 	case common.StatusInit:
 		if sb.AfterInit != nil {
 			err = sb.AfterInit(ctx, sb.Deps...)
 		}
 	case common.StatusSetup:
+		// Services constructed by scopes resolve their own dependencies
+		if len(sb.slots) > 0 && !sb.scoped {
+			if sb.Deps, err = sb.dependencies(ctx); err != nil {
+				return err
+			}
+		}
+		if err = sb.inject(ctx); err != nil {
+			return err
+		}
 		if s, ok := sb.instance.(IServiceSetup); ok {
 			err = s.Setup(ctx, sb.Deps...)
+			if p, ok := s.(*provider); ok && err == nil && p.value != nil {
+				sb.setInstance(p.value)
+			}
 		} else {
 			sb.Log("Service %s does not implement IServiceSetup", logKey)
 		}
@@ -165,14 +395,7 @@ func (sb *Service) Run(ctx context.Context, ss common.ServiceStatus) (err error)
 		if s, ok := sb.instance.(IServiceStart); ok {
 			err = s.Start(ctx)
 		} else if s, ok := sb.instance.(IServiceStartServer); ok {
-			var chErr = make(chan error)
-			go func(ctx context.Context) {
-				defer close(chErr)
-				s.StartServer(ctx, func(e error) {
-					chErr <- e
-				})
-			}(ctx)
-			err = <-chErr
+			err = sb.startServer(ctx, s)
 		} else {
 			sb.Log("Service %s does not implement IServiceStart", logKey)
 		}
@@ -185,18 +408,29 @@ func (sb *Service) Run(ctx context.Context, ss common.ServiceStatus) (err error)
 	default:
 		err = nil
 	}
-
-	if err != nil {
-		return err
-	}
-	sb.status = ss
-	return nil
+	return err
 }
 
 func (sb *Service) IsRunAsync(ss common.ServiceStatus) bool {
 	return sb.AsyncMode[ss]
 }
 
+// Resources returns the resource classes used by the service in the lifecycle phase.
+func (sb *Service) Resources(ss common.ServiceStatus) map[string]int {
+	if resources, ok := sb.PhaseResources[ss]; ok {
+		return resources
+	}
+	return sb.ServiceLifeCycle.Resources
+}
+
+// Priority returns the priority of the service in the lifecycle phase.
+func (sb *Service) Priority(ss common.ServiceStatus) int {
+	if priority, ok := sb.Priorities[ss]; ok {
+		return priority
+	}
+	return sb.ServiceLifeCycle.Priority
+}
+
 func (sb *Service) UpdateDependencies(dep *Service) {
 	sb.following = append(sb.following, dep)
 	dep.followers = append(dep.followers, sb)
@@ -206,3 +440,113 @@ func (sb *Service) UpdateDependencies(dep *Service) {
 		logServiceKey, logKey, len(sb.following), logKey, logServiceKey, len(dep.followers),
 	)
 }
+
+// toServices filters services out of a list of tasks.
+func toServices(tasks []types.ITask) []*Service {
+	services := make([]*Service, 0, len(tasks))
+	for _, task := range tasks {
+		if sb, ok := task.(*Service); ok {
+			services = append(services, sb)
+		}
+	}
+	return services
+}
+
+// linkDependency makes the service depend on dep and records where the dependency was declared.
+func (sb *Service) linkDependency(dep *Service, origin EdgeOrigin) {
+	sb.UpdateDependencies(dep)
+	if _, ok := sb.origins[dep.name]; !ok {
+		sb.origins[dep.name] = origin
+	}
+}
+
+func (sb *Service) getInstance() IService {
+	sb.rwStatus.RLock()
+	defer sb.rwStatus.RUnlock()
+	return sb.instance
+}
+
+func (sb *Service) setInstance(instance IService) {
+	sb.rwStatus.Lock()
+	defer sb.rwStatus.Unlock()
+	sb.instance = instance
+}
+
+// instanceType returns the type of the service instance. For services registered by Provide,
+// it is the result type of the constructor, even before the constructor is called.
+func (sb *Service) instanceType() reflect.Type {
+	instance := sb.getInstance()
+	if p, ok := instance.(*provider); ok {
+		return p.out
+	}
+	return reflect.TypeOf(instance)
+}
+
+// resolvedDeps returns the instances of the dependencies of the service. Dependencies registered by Provide
+// are nil until their constructor is called.
+func (sb *Service) resolvedDeps() Dependencies {
+	deps := make(Dependencies, 0, len(sb.slots))
+	for _, slot := range sb.slots {
+		if slot.group != "" {
+			members := make(Dependencies, 0, len(slot.members))
+			for _, member := range slot.members {
+				members = append(members, member.value())
+			}
+			deps = append(deps, members)
+			continue
+		}
+		if slot.service == nil {
+			deps = append(deps, nil)
+			continue
+		}
+		deps = append(deps, slot.service.value())
+	}
+	return deps
+}
+
+// value returns the service instance, or nil if it is a constructor which has not been called yet.
+func (sb *Service) value() IService {
+	instance := sb.getInstance()
+	if _, ok := instance.(*provider); ok {
+		return nil
+	}
+	return instance
+}
+
+// dependencies returns the instances of the dependencies of the service at Setup.
+// A new instance is constructed for each transient dependency.
+func (sb *Service) dependencies(ctx context.Context) (Dependencies, error) {
+	deps := make(Dependencies, 0, len(sb.slots))
+	for _, slot := range sb.slots {
+		if slot.group != "" {
+			members := make(Dependencies, 0, len(slot.members))
+			for _, member := range slot.members {
+				dep, err := member.resolve(ctx)
+				if err != nil {
+					return nil, err
+				}
+				members = append(members, dep)
+			}
+			deps = append(deps, members)
+			continue
+		}
+		if slot.service == nil {
+			deps = append(deps, nil)
+			continue
+		}
+		dep, err := slot.service.resolve(ctx)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
+
+// resolve returns the instance of the service for one of its dependents.
+func (sb *Service) resolve(ctx context.Context) (IService, error) {
+	if p, ok := sb.getInstance().(*provider); ok && p.scope == ScopeTransient {
+		return p.create(ctx)
+	}
+	return sb.value(), nil
+}
@@ -0,0 +1,42 @@
+package gobs
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/xarest/gobs/common"
+	"github.com/xarest/gobs/utils"
+)
+
+// Supply registers a value which is ready to use, e.g. a configuration struct, a *sql.DB or a time.Duration.
+// The value is a node of the graph which dependents reference by its key, or by its type like other services,
+// but none of its lifecycle methods are called. If key is not provided, the default key of the type of the
+// value is used, or the name of the type for unnamed and builtin types (e.g. "time.Duration", "string").
+// A value supplied with a key which is already registered returns common.ErrorServiceExists.
+//
+// Example:
+//
+//	bs.Supply(cfg) // Dependents declare new(Config) in Deps and assign it to a Config field
+//	bs.Supply(5*time.Second, "http-timeout")
+func (bs *Bootstrap) Supply(value IService, key ...string) error {
+	if value == nil {
+		return fmt.Errorf("supplied value must not be nil: %w", common.ErrorInvalidType)
+	}
+	k := ""
+	if len(key) > 0 {
+		k = key[0]
+	}
+	if k == "" {
+		k = utils.TypeName(reflect.TypeOf(value))
+	}
+	if bs.keys[k] != nil {
+		return fmt.Errorf("supplied value %s: %w", k, common.ErrorServiceExists)
+	}
+	sBlock := NewService(value, k, common.StatusUninitialized, bs.Logger.Clone())
+	sBlock.supplied = true
+	bs.keys[k] = sBlock
+	bs.services = append(bs.services, sBlock)
+	bs.LogS("Value %s is supplied", k)
+	bs.emit(Event{Type: EventServiceAdded, Service: k, Phase: sBlock.status})
+	return nil
+}
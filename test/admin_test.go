@@ -0,0 +1,70 @@
+package gobs_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/admin"
+)
+
+func (s *BootstrapSuit) TestAdminHandler() {
+	t := s.T()
+	ctx := context.TODO()
+	db := &KeyedService{key: "DB"}
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "API", deps: []string{"DB"}}, "API"))
+	require.NoError(t, bs.AddDefault(db, "DB"))
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+
+	mux := http.NewServeMux()
+	mux.Handle("/admin/", http.StripPrefix("/admin", admin.NewHandler(bs)))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	get := func(path string, out any) int {
+		res, err := http.Get(server.URL + path)
+		require.NoError(t, err, "GET %s expected no error", path)
+		defer res.Body.Close()
+		assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(res.Body).Decode(out), "Expected JSON body")
+		return res.StatusCode
+	}
+
+	var services []struct {
+		Key          string                     `json:"key"`
+		Status       string                     `json:"status"`
+		Dependencies []string                   `json:"dependencies"`
+		Followers    []string                   `json:"followers"`
+		Timings      map[string]json.RawMessage `json:"timings"`
+	}
+	require.Equal(t, http.StatusOK, get("/admin/services", &services))
+	require.Len(t, services, 2)
+	assert.Equal(t, "API", services[0].Key)
+	assert.Equal(t, "Setup", services[0].Status)
+	assert.Equal(t, []string{"DB"}, services[0].Dependencies)
+	assert.Equal(t, []string{"API"}, services[1].Followers)
+	assert.Contains(t, services[0].Timings, "Setup")
+
+	var health struct {
+		Status string `json:"status"`
+		Ready  bool   `json:"ready"`
+	}
+	assert.Equal(t, http.StatusServiceUnavailable, get("/admin/readyz", &health), "Expected not ready before Start")
+	assert.Equal(t, http.StatusOK, get("/admin/livez", &health))
+
+	require.NoError(t, bs.Start(ctx), "Start expected no error")
+	assert.Equal(t, http.StatusOK, get("/admin/readyz", &health))
+	assert.True(t, health.Ready)
+
+	db.healthErr = assert.AnError
+	assert.Equal(t, http.StatusServiceUnavailable, get("/admin/livez", &health))
+	require.Equal(t, http.StatusOK, get("/admin/health", &health))
+	assert.Equal(t, "Down", health.Status)
+	require.NoError(t, bs.Stop(ctx), "Stop expected no error")
+}
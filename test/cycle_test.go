@@ -0,0 +1,71 @@
+package gobs_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/common"
+	"github.com/xarest/gobs/utils"
+)
+
+type CycleA struct{}
+
+func (c *CycleA) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{Deps: gobs.Dependencies{new(CycleB)}}, nil
+}
+
+type CycleB struct{}
+
+func (c *CycleB) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{Deps: gobs.Dependencies{new(CycleC)}}, nil
+}
+
+type CycleC struct{}
+
+func (c *CycleC) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{Deps: gobs.Dependencies{new(CycleA)}}, nil
+}
+
+type KeyedCycle struct {
+	next string
+}
+
+func (c *KeyedCycle) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{
+		ExtraDeps: []gobs.CustomService{
+			{Name: c.next, Instance: &KeyedCycle{next: "K1"}},
+		},
+	}, nil
+}
+
+func (s *BootstrapSuit) TestDependencyCycle() {
+	t := s.T()
+	bs := gobs.NewBootstrap(gobs.Config{NumOfConcurrencies: 0})
+	require.NoError(t, bs.AddDefault(new(CycleA)), "AddDefault expected no error")
+
+	err := bs.Init(context.TODO())
+	require.ErrorIs(t, err, common.ErrorDependencyCycle, "Init expected cycle error")
+	var cycleErr *common.CycleError
+	require.True(t, errors.As(err, &cycleErr), "Expected error is CycleError")
+	assert.Equal(t, []string{
+		utils.DefaultServiceName(CycleA{}),
+		utils.DefaultServiceName(CycleB{}),
+		utils.DefaultServiceName(CycleC{}),
+		utils.DefaultServiceName(CycleA{}),
+	}, cycleErr.Path)
+}
+
+func (s *BootstrapSuit) TestDependencyCycleExtraDeps() {
+	t := s.T()
+	bs := gobs.NewBootstrap(gobs.Config{NumOfConcurrencies: gobs.DEFAULT_MAX_CONCURRENT})
+	require.NoError(t, bs.AddDefault(&KeyedCycle{next: "K2"}, "K1"), "AddDefault expected no error")
+
+	err := bs.Init(context.TODO())
+	var cycleErr *common.CycleError
+	require.True(t, errors.As(err, &cycleErr), "Expected error is CycleError")
+	assert.Equal(t, []string{"K1", "K2", "K1"}, cycleErr.Path)
+	assert.Equal(t, "dependency cycle: K1 -> K2 -> K1", err.Error())
+}
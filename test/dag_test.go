@@ -0,0 +1,146 @@
+package gobs_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs/common"
+	"github.com/xarest/gobs/scheduler"
+)
+
+func sumDeps(value int) func(ctx context.Context, deps map[string]int) (int, error) {
+	return func(ctx context.Context, deps map[string]int) (int, error) {
+		for _, dep := range deps {
+			value += dep
+		}
+		return value, nil
+	}
+}
+
+func (s *SchedulerSuit) TestRunDAG() {
+	for _, concurrency := range []int{-1, 0, 1} {
+		t := s.T()
+		var mutex sync.Mutex
+		var progress []scheduler.Progress
+		results, err := scheduler.RunDAG(context.TODO(), []scheduler.Task[int]{
+			{Name: "total", DependsOn: []string{"left", "right"}, Run: sumDeps(0)},
+			{Name: "left", DependsOn: []string{"base"}, Async: true, Run: sumDeps(10)},
+			{Name: "right", DependsOn: []string{"base"}, Async: true, Run: sumDeps(100)},
+			{Name: "base", Run: sumDeps(1)},
+		}, scheduler.Options{
+			Concurrency: concurrency,
+			OnProgress: func(p scheduler.Progress) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				progress = append(progress, p)
+			},
+		})
+		require.NoError(t, err, "RunDAG expected no error")
+		require.Len(t, results, 4)
+		assert.Equal(t, 1, results["base"].Value)
+		assert.Equal(t, 11, results["left"].Value)
+		assert.Equal(t, 101, results["right"].Value)
+		assert.Equal(t, 112, results["total"].Value, "Expected results of dependencies are passed to tasks")
+		for _, res := range results {
+			assert.False(t, res.Skipped)
+			assert.False(t, res.EndedAt.Before(res.StartedAt))
+		}
+
+		last := progress[len(progress)-1]
+		assert.Equal(t, "total", last.Task)
+		assert.Equal(t, scheduler.TaskSucceeded, last.State)
+		assert.Equal(t, 4, last.Done)
+		assert.Equal(t, 4, last.Total)
+	}
+}
+
+func (s *SchedulerSuit) TestRunDAGError() {
+	t := s.T()
+	errFetch := errors.New("fetch failed")
+	results, err := scheduler.RunDAG(context.TODO(), []scheduler.Task[string]{
+		{Name: "fetch", Run: func(ctx context.Context, deps map[string]string) (string, error) {
+			return "", errFetch
+		}},
+		{Name: "compile", DependsOn: []string{"fetch"}, Run: func(ctx context.Context, deps map[string]string) (string, error) {
+			return "bin", nil
+		}},
+		{Name: "test", DependsOn: []string{"compile"}, Run: func(ctx context.Context, deps map[string]string) (string, error) {
+			return "ok", nil
+		}},
+	}, scheduler.Options{Concurrency: -1})
+	require.Error(t, err, "RunDAG expected error")
+	assert.ErrorIs(t, err, errFetch)
+	var runErr *scheduler.RunError
+	require.True(t, errors.As(err, &runErr), "Expected *scheduler.RunError")
+	require.Len(t, runErr.Errors, 1)
+	assert.Equal(t, "fetch", runErr.Errors[0].Task)
+	assert.ElementsMatch(t, []string{"compile", "test"}, runErr.Errors[0].Skipped)
+	assert.ErrorIs(t, results["fetch"].Err, errFetch, "Expected error of the task in its result")
+	assert.True(t, results["compile"].Skipped)
+	assert.True(t, results["test"].Skipped)
+}
+
+func (s *SchedulerSuit) TestRunDAGFirstError() {
+	fail := func(ctx context.Context, deps map[string]int) (int, error) {
+		return 0, assert.AnError
+	}
+	for _, concurrency := range []int{-1, 0} {
+		t := s.T()
+		results, err := scheduler.RunDAG(context.TODO(), []scheduler.Task[int]{
+			{Name: "a", Run: fail},
+			{Name: "b", Run: fail},
+			{Name: "c", Run: sumDeps(1)},
+		}, scheduler.Options{Concurrency: concurrency})
+		var runErr *scheduler.RunError
+		require.True(t, errors.As(err, &runErr), "Expected *scheduler.RunError")
+		require.Len(t, runErr.Errors, 1, "Expected only the first failure of sync tasks")
+		assert.Equal(t, "a", runErr.Errors[0].Task)
+		assert.True(t, results["b"].Skipped, "Expected no task is dispatched after the first failure")
+		assert.True(t, results["c"].Skipped, "Expected no task is dispatched after the first failure")
+	}
+}
+
+func (s *SchedulerSuit) TestRunDAGCancel() {
+	t := s.T()
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	results, err := scheduler.RunDAG(ctx, []scheduler.Task[bool]{
+		{Name: "slow", Async: true, Run: func(ctx context.Context, deps map[string]bool) (bool, error) {
+			cancel()
+			time.Sleep(20 * time.Millisecond)
+			return true, nil
+		}},
+		{Name: "next", DependsOn: []string{"slow"}, Run: func(ctx context.Context, deps map[string]bool) (bool, error) {
+			return true, nil
+		}},
+	}, scheduler.Options{Concurrency: -1})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, results["slow"].Value, "Expected running tasks are awaited")
+	assert.True(t, results["next"].Skipped, "Expected pending tasks are skipped")
+}
+
+func (s *SchedulerSuit) TestRunDAGInvalid() {
+	t := s.T()
+	run := sumDeps(0)
+	_, err := scheduler.RunDAG(context.TODO(), []scheduler.Task[int]{
+		{Name: "a", DependsOn: []string{"b"}, Run: run},
+		{Name: "b", DependsOn: []string{"a"}, Run: run},
+	}, scheduler.Options{})
+	var cycleErr *common.CycleError
+	require.True(t, errors.As(err, &cycleErr), "Expected cycle error, got %v", err)
+	assert.Len(t, cycleErr.Path, 3)
+
+	_, err = scheduler.RunDAG(context.TODO(), []scheduler.Task[int]{
+		{Name: "a", DependsOn: []string{"missing"}, Run: run},
+	}, scheduler.Options{})
+	assert.ErrorIs(t, err, common.ErrorServiceNotFound)
+
+	_, err = scheduler.RunDAG(context.TODO(), []scheduler.Task[int]{
+		{Name: "a", Run: run}, {Name: "a", Run: run},
+	}, scheduler.Options{})
+	assert.ErrorIs(t, err, common.ErrorInvalidType)
+}
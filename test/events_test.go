@@ -0,0 +1,91 @@
+package gobs_test
+
+import (
+	"context"
+	"sync"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/common"
+)
+
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []gobs.Event
+}
+
+func (r *eventRecorder) Record(e gobs.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+func (r *eventRecorder) Filter(eventType gobs.EventType, phase common.ServiceStatus) []gobs.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []gobs.Event
+	for _, e := range r.events {
+		if e.Type == eventType && e.Phase == phase {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (s *BootstrapSuit) TestEvents() {
+	t := s.T()
+	ctx := context.TODO()
+	events := &eventRecorder{}
+	bs := gobs.NewBootstrap()
+	unsubscribe := bs.Subscribe(events.Record)
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "API", deps: []string{"DB"}}, "API"))
+	require.NoError(t, bs.AddDefault(&ShutdownService{setupErr: assert.AnError}, "DB"))
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.Error(t, bs.Setup(ctx), "Setup expected error")
+
+	added := events.Filter(gobs.EventServiceAdded, common.StatusUninitialized)
+	require.Len(t, added, 2)
+	assert.Equal(t, "API", added[0].Service)
+	assert.Equal(t, "DB", added[1].Service)
+
+	require.Len(t, events.Filter(gobs.EventPhaseStarted, common.StatusSetup), 1)
+	finished := events.Filter(gobs.EventPhaseFinished, common.StatusSetup)
+	require.Len(t, finished, 1)
+	assert.ErrorIs(t, finished[0].Err, assert.AnError)
+	assert.False(t, finished[0].EndedAt.Before(finished[0].StartedAt))
+
+	assert.Len(t, events.Filter(gobs.EventServiceSucceeded, common.StatusInit), 2)
+	assert.Len(t, events.Filter(gobs.EventServiceRunning, common.StatusSetup), 1)
+	failed := events.Filter(gobs.EventServiceFailed, common.StatusSetup)
+	require.Len(t, failed, 1)
+	assert.Equal(t, "DB", failed[0].Service)
+	assert.ErrorIs(t, failed[0].Err, assert.AnError)
+	ready := events.Filter(gobs.EventServiceReady, common.StatusSetup)
+	require.Len(t, ready, 1, "Expected API is never ready")
+	assert.Equal(t, "DB", ready[0].Service)
+
+	unsubscribe()
+	require.NoError(t, bs.Stop(ctx), "Stop expected no error")
+	assert.Empty(t, events.Filter(gobs.EventPhaseStarted, common.StatusStop), "Expected no event after unsubscribe")
+}
+
+func (s *BootstrapSuit) TestEventsSubscriberOrder() {
+	t := s.T()
+	var calls []int
+	bs := gobs.NewBootstrap()
+	unsubscribes := make([]func(), 0, 5)
+	for i := 0; i < 5; i++ {
+		unsubscribes = append(unsubscribes, bs.Subscribe(func(e gobs.Event) {
+			calls = append(calls, i)
+		}))
+	}
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "API"}, "API"))
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, calls, "Expected subscribers are called in order")
+
+	calls = nil
+	unsubscribes[1]()
+	unsubscribes[3]()
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "Worker"}, "Worker"))
+	assert.Equal(t, []int{0, 2, 4}, calls, "Expected subscribers keep their order after unsubscribe")
+}
@@ -0,0 +1,63 @@
+package gobs_test
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/utils"
+)
+
+func (s *BootstrapSuit) TestGraph() {
+	t := s.T()
+	ctx := context.TODO()
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "API", deps: []string{"DB"}}, "API"))
+	require.NoError(t, bs.AddDefault(new(C)), "AddDefault expected no error")
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "DB"}, "DB"))
+	_, err := bs.Graph()
+	require.Error(t, err, "Graph expected error before Init")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+
+	g, err := bs.Graph()
+	require.NoError(t, err, "Graph expected no error")
+	keyA, keyB, keyC := utils.DefaultServiceName(A{}), utils.DefaultServiceName(B{}), utils.DefaultServiceName(C{})
+	require.Len(t, g.Nodes, 5)
+	assert.Equal(t, "API", g.Nodes[0].Key)
+	assert.Equal(t, "*gobs_test.KeyedService", g.Nodes[0].Type)
+	assert.Equal(t, keyC, g.Nodes[1].Key)
+	assert.Equal(t, "DB", g.Nodes[2].Key)
+	assert.Equal(t, keyA, g.Nodes[3].Key)
+	assert.Equal(t, []gobs.GraphEdge{
+		{From: "API", To: "DB", Origin: gobs.OriginExtraDeps},
+		{From: keyC, To: keyA, Origin: gobs.OriginDeps},
+		{From: keyC, To: keyB, Origin: gobs.OriginDeps},
+		{From: keyB, To: keyA, Origin: gobs.OriginDeps},
+	}, g.Edges)
+
+	dot := g.DOT()
+	assert.Contains(t, dot, "digraph gobs {")
+	assert.Contains(t, dot, `"API" -> "DB" [label="ExtraDeps"];`)
+
+	mermaid := g.Mermaid()
+	assert.Contains(t, mermaid, "flowchart LR")
+	assert.Contains(t, mermaid, `n0["API<br/>Init"]`)
+	assert.Contains(t, mermaid, "n0 -->|ExtraDeps| n2")
+
+	data, err := g.JSON()
+	require.NoError(t, err, "JSON expected no error")
+	var decoded struct {
+		Nodes []struct {
+			Key    string          `json:"key"`
+			Async  map[string]bool `json:"async"`
+			Status string          `json:"status"`
+		} `json:"nodes"`
+		Edges []gobs.GraphEdge `json:"edges"`
+	}
+	require.NoError(t, json.Unmarshal(data, &decoded), "Expected valid JSON")
+	assert.Equal(t, "Init", decoded.Nodes[0].Status)
+	assert.Equal(t, map[string]bool{"Init": false, "Setup": false, "Start": false, "Stop": false}, decoded.Nodes[0].Async)
+	assert.Equal(t, g.Edges, decoded.Edges)
+}
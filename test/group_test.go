@@ -0,0 +1,101 @@
+package gobs_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/common"
+)
+
+type Route interface {
+	Path() string
+}
+
+type UserRoute struct {
+	ready bool
+}
+
+func (s *UserRoute) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{Groups: []string{"http-routes"}}, nil
+}
+
+func (s *UserRoute) Setup(ctx context.Context, deps ...gobs.IService) error {
+	s.ready = true
+	return nil
+}
+
+func (s *UserRoute) Path() string { return "/users" }
+
+type OrderRoute struct{}
+
+func (s *OrderRoute) Path() string { return "/orders" }
+
+type Router struct {
+	Routes  []Route
+	Metrics []Route
+	ready   []bool
+}
+
+func (s *Router) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{
+		Deps: gobs.Dependencies{gobs.Group("http-routes"), gobs.Group("metrics")},
+	}, nil
+}
+
+func (s *Router) Setup(ctx context.Context, deps ...gobs.IService) error {
+	routes, err := gobs.Members[Route](deps[0])
+	if err != nil {
+		return err
+	}
+	for _, route := range routes {
+		if r, ok := route.(*UserRoute); ok {
+			s.ready = append(s.ready, r.ready)
+		}
+	}
+	return gobs.Dependencies(deps).Assign(&s.Routes, &s.Metrics)
+}
+
+func (s *BootstrapSuit) TestGroup() {
+	t := s.T()
+	ctx := context.TODO()
+	router := &Router{}
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(router), "AddDefault expected no error")
+	require.NoError(t, bs.AddDefault(&UserRoute{}), "AddDefault expected no error")
+	require.NoError(t, bs.Provide(func() *OrderRoute {
+		return &OrderRoute{}
+	}, gobs.InGroup("http-routes")), "Provide expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+
+	require.Len(t, router.Routes, 2, "Expected all members of the group")
+	assert.Equal(t, "/users", router.Routes[0].Path())
+	assert.Equal(t, "/orders", router.Routes[1].Path())
+	assert.Equal(t, []bool{true}, router.ready, "Expected members finished Setup before the consumer")
+	assert.NotNil(t, router.Metrics, "Expected empty group is assigned")
+	assert.Empty(t, router.Metrics, "Expected empty group has no members")
+
+	g, err := bs.Graph()
+	require.NoError(t, err, "Graph expected no error")
+	var groupEdges int
+	for _, edge := range g.Edges {
+		if edge.Origin == gobs.OriginGroup {
+			groupEdges++
+		}
+	}
+	assert.Equal(t, 2, groupEdges, "Expected every member is a dependency of the consumer")
+}
+
+func (s *BootstrapSuit) TestGroupMembersInvalidType() {
+	t := s.T()
+	_, err := gobs.Members[Route](&UserRoute{})
+	assert.True(t, errors.Is(err, common.ErrorInvalidType), "Expected error for non group, got %v", err)
+	_, err = gobs.Members[Route](gobs.Dependencies{&A{}})
+	assert.True(t, errors.Is(err, common.ErrorInvalidType), "Expected error for invalid member, got %v", err)
+	var routes []Route
+	err = gobs.Dependencies{gobs.Dependencies{&A{}}}.Assign(&routes)
+	assert.True(t, errors.Is(err, common.ErrorInvalidType), "Expected error for invalid member, got %v", err)
+}
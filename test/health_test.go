@@ -0,0 +1,60 @@
+package gobs_test
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+)
+
+func (s *BootstrapSuit) TestHealth() {
+	t := s.T()
+	ctx := context.TODO()
+	db := &KeyedService{key: "DB"}
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "API", deps: []string{"Repo"}}, "API"))
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "Repo", deps: []string{"DB"}}, "Repo"))
+	require.NoError(t, bs.AddDefault(db, "DB"))
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "Cache"}, "Cache"))
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+
+	report := bs.Health(ctx)
+	assert.Equal(t, gobs.HealthUnknown, report.Status)
+	assert.False(t, report.Started(), "Expected services are not started")
+	assert.False(t, report.Ready(), "Expected services are not ready")
+	assert.True(t, report.Live(), "Expected services are live")
+
+	require.NoError(t, bs.Start(ctx), "Start expected no error")
+	report = bs.Health(ctx)
+	assert.Equal(t, gobs.HealthUp, report.Status)
+	assert.True(t, report.Started(), "Expected services are started")
+	assert.True(t, report.Ready(), "Expected services are ready")
+
+	db.healthErr = assert.AnError
+	report = bs.Health(ctx)
+	assert.Equal(t, gobs.HealthDown, report.Status)
+	assert.False(t, report.Live(), "Expected DB is not live")
+	assert.False(t, report.Ready(), "Expected services are not ready")
+
+	dbHealth := report.Find("DB")
+	require.NotNil(t, dbHealth)
+	assert.Equal(t, gobs.HealthDown, dbHealth.Status)
+	assert.ErrorIs(t, dbHealth.Err, assert.AnError)
+
+	apiHealth := report.Find("API")
+	require.NotNil(t, apiHealth)
+	assert.Equal(t, gobs.HealthDegraded, apiHealth.Status)
+	assert.True(t, apiHealth.Live, "Expected API itself is live")
+	assert.False(t, apiHealth.Ready, "Expected API is not ready")
+	assert.Equal(t, []string{"DB"}, apiHealth.Unhealthy)
+
+	cacheHealth := report.Find("Cache")
+	require.NotNil(t, cacheHealth)
+	assert.Equal(t, gobs.HealthUp, cacheHealth.Status)
+	assert.True(t, cacheHealth.Ready, "Expected Cache is ready")
+
+	require.NoError(t, bs.Stop(ctx), "Stop expected no error")
+	assert.False(t, bs.Health(ctx).Started(), "Expected stopped services are not started")
+}
@@ -0,0 +1,102 @@
+package gobs_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/common"
+)
+
+type InjectedB struct{}
+
+type InjectedC struct {
+	Name string
+}
+
+type InjectedD struct {
+	B       *InjectedB `gobs:"inject"`
+	C       *InjectedC `gobs:"inject,key=C1"`
+	Storage Storage    `gobs:"inject"`
+	Cache   *InjectedC `gobs:"inject,key=C2,optional"`
+	Plain   *InjectedB
+	setupB  *InjectedB
+}
+
+func (s *InjectedD) Setup(ctx context.Context, deps ...gobs.IService) error {
+	s.setupB = s.B
+	return nil
+}
+
+type InjectedUnexported struct {
+	b *InjectedB `gobs:"inject"`
+}
+
+type InjectedKeyedStorage struct {
+	Storage Storage `gobs:"inject,key=S1"`
+}
+
+type InjectedNoKey struct {
+	Name string `gobs:"inject"`
+}
+
+func (s *BootstrapSuit) TestInjection() {
+	t := s.T()
+	ctx := context.TODO()
+	d := &InjectedD{}
+	c1 := &InjectedC{Name: "C1"}
+	bs := gobs.NewBootstrap(gobs.Config{EnableInjection: true})
+	require.NoError(t, bs.AddDefault(d), "AddDefault expected no error")
+	require.NoError(t, bs.AddDefault(c1, "C1"), "AddDefault expected no error")
+	require.NoError(t, bs.AddDefault(&DiskStorage{}), "AddDefault expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+
+	assert.NotNil(t, d.B, "Expected pointer dependency is created and injected")
+	assert.Same(t, d.B, d.setupB, "Expected fields are injected before Setup")
+	assert.Same(t, c1, d.C, "Expected keyed dependency is injected")
+	assert.Equal(t, "C1", d.C.Name)
+	assert.IsType(t, &DiskStorage{}, d.Storage, "Expected interface dependency is injected")
+	assert.Nil(t, d.Cache, "Expected missing optional dependency is not injected")
+	assert.Nil(t, d.Plain, "Expected untagged field is untouched")
+
+	g, err := bs.Graph()
+	require.NoError(t, err, "Graph expected no error")
+	assert.Len(t, g.Nodes, 4)
+	assert.Len(t, g.Edges, 3)
+	for _, edge := range g.Edges {
+		assert.Equal(t, gobs.OriginInject, edge.Origin)
+	}
+}
+
+func (s *BootstrapSuit) TestInjectionDisabled() {
+	t := s.T()
+	ctx := context.TODO()
+	d := &InjectedD{}
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(d), "AddDefault expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+	assert.Nil(t, d.B, "Expected no injection when it is disabled")
+}
+
+func (s *BootstrapSuit) TestInjectionMissingDependency() {
+	t := s.T()
+	ctx := context.TODO()
+	bs := gobs.NewBootstrap(gobs.Config{EnableInjection: true})
+	require.NoError(t, bs.AddDefault(&InjectedKeyedStorage{}), "AddDefault expected no error")
+	require.NoError(t, bs.AddDefault(&DiskStorage{}), "AddDefault expected no error")
+	err := bs.Init(ctx)
+	assert.True(t, errors.Is(err, common.ErrorServiceNotFound), "Expected error for missing keyed dependency, got %v", err)
+}
+
+func (s *BootstrapSuit) TestInjectionInvalidTag() {
+	t := s.T()
+	bs := gobs.NewBootstrap(gobs.Config{EnableInjection: true})
+	err := bs.AddDefault(&InjectedUnexported{})
+	assert.True(t, errors.Is(err, common.ErrorInvalidType), "Expected error for unexported field, got %v", err)
+	err = bs.AddDefault(&InjectedNoKey{})
+	assert.True(t, errors.Is(err, common.ErrorInvalidType), "Expected error for field without key, got %v", err)
+}
@@ -0,0 +1,116 @@
+package gobs_test
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/common"
+)
+
+type Storage interface {
+	Put(key string) error
+}
+
+type DiskStorage struct{}
+
+func (s *DiskStorage) Put(key string) error { return nil }
+
+type MemoryStorage struct{}
+
+func (s *MemoryStorage) Put(key string) error { return nil }
+
+type StorageConsumer struct {
+	Storage Storage
+	Cache   Storage
+}
+
+func (s *StorageConsumer) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{
+		Deps: gobs.Dependencies{gobs.Iface[Storage]()},
+		ExtraDeps: []gobs.CustomService{
+			{Service: gobs.Iface[Storage](), Optional: true},
+		},
+	}, nil
+}
+
+func (s *StorageConsumer) Setup(ctx context.Context, deps ...gobs.IService) error {
+	return gobs.Dependencies(deps).Assign(&s.Storage, &s.Cache)
+}
+
+func (s *BootstrapSuit) TestInterfaceDependency() {
+	t := s.T()
+	ctx := context.TODO()
+	consumer, disk := &StorageConsumer{}, &DiskStorage{}
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(consumer), "AddDefault expected no error")
+	require.NoError(t, bs.AddDefault(disk), "AddDefault expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+	assert.Same(t, disk, consumer.Storage, "Expected the single implementation is resolved")
+	assert.Same(t, disk, consumer.Cache, "Expected the single implementation is resolved")
+}
+
+func (s *BootstrapSuit) TestInterfaceDependencyNotFound() {
+	t := s.T()
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(&StorageConsumer{}), "AddDefault expected no error")
+	err := bs.Init(context.TODO())
+	require.ErrorIs(t, err, common.ErrorInterfaceNotFound, "Init expected not found error")
+	assert.Contains(t, err.Error(), "gobs_test.Storage")
+}
+
+func (s *BootstrapSuit) TestInterfaceDependencyAmbiguous() {
+	t := s.T()
+	ctx := context.TODO()
+	consumer, memory := &StorageConsumer{}, &MemoryStorage{}
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(consumer), "AddDefault expected no error")
+	require.NoError(t, bs.AddDefault(&DiskStorage{}), "AddDefault expected no error")
+	require.NoError(t, bs.AddDefault(memory, "memory"), "AddDefault expected no error")
+	err := bs.Init(ctx)
+	require.ErrorIs(t, err, common.ErrorAmbiguousInterface, "Init expected ambiguous error")
+	assert.Contains(t, err.Error(), "memory")
+
+	bs = gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(consumer), "AddDefault expected no error")
+	require.NoError(t, bs.AddDefault(&DiskStorage{}), "AddDefault expected no error")
+	require.NoError(t, bs.AddDefault(memory, "memory"), "AddDefault expected no error")
+	gobs.Bind[Storage](bs, "memory")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+	assert.Same(t, memory, consumer.Storage, "Expected the bound implementation is resolved")
+}
+
+type KeyedStorageConsumer struct {
+	Storage Storage
+}
+
+func (s *KeyedStorageConsumer) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{
+		ExtraDeps: []gobs.CustomService{{Service: gobs.Iface[Storage](), Name: "disk"}},
+	}, nil
+}
+
+func (s *KeyedStorageConsumer) Setup(ctx context.Context, deps ...gobs.IService) error {
+	return gobs.Dependencies(deps).Assign(&s.Storage)
+}
+
+func (s *BootstrapSuit) TestInterfaceDependencyKeyed() {
+	t := s.T()
+	ctx := context.TODO()
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(&KeyedStorageConsumer{}), "AddDefault expected no error")
+	err := bs.Init(ctx)
+	require.ErrorIs(t, err, common.ErrorServiceNotFound, "Expected the interface marker is not registered as a service")
+	assert.Contains(t, err.Error(), "disk")
+
+	consumer, disk := &KeyedStorageConsumer{}, &DiskStorage{}
+	bs = gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(consumer), "AddDefault expected no error")
+	require.NoError(t, bs.AddDefault(disk, "disk"), "AddDefault expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+	assert.Same(t, disk, consumer.Storage, "Expected the service of the key is resolved")
+}
@@ -0,0 +1,108 @@
+package gobs_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/common"
+)
+
+var errStopFailed = errors.New("stop failed")
+
+func (s *BootstrapSuit) TestLifecycleErrorOnSetup() {
+	t := s.T()
+	ctx := context.TODO()
+	recorder := &callRecorder{}
+	errDB := errors.New("db is down")
+	bs := gobs.NewBootstrap(gobs.Config{
+		NumOfConcurrencies: gobs.DEFAULT_MAX_CONCURRENT,
+	})
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "Log", recorder: recorder}, "Log"))
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "DB", async: true, setupDelay: 20 * time.Millisecond, setupErr: errDB, recorder: recorder}, "DB"))
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "Cache", async: true, setupDelay: 40 * time.Millisecond, setupErr: assert.AnError, recorder: recorder}, "Cache"))
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "Repo", deps: []string{"DB"}, recorder: recorder}, "Repo"))
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "API", deps: []string{"Repo", "Cache"}, recorder: recorder}, "API"))
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+
+	err := bs.Setup(ctx)
+	require.Error(t, err, "Setup expected error")
+
+	var lErr *common.LifecycleError
+	require.True(t, errors.As(err, &lErr), "Expected error is LifecycleError")
+	assert.Equal(t, common.StatusSetup, lErr.Phase)
+	require.Len(t, lErr.Errors, 2, "Expected both DB and Cache failures are reported")
+
+	assert.ErrorIs(t, err, errDB)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.ErrorIs(t, err, &common.ServiceError{Service: "DB", Phase: common.StatusSetup})
+	assert.NotErrorIs(t, err, &common.ServiceError{Service: "DB", Phase: common.StatusStart})
+
+	dbErr := lErr.Find("DB")
+	require.NotNil(t, dbErr, "Expected DB failure is found")
+	assert.Equal(t, errDB, dbErr.Err)
+	assert.Contains(t, dbErr.Skipped, "Repo")
+	assert.Contains(t, lErr.Skipped(), "API")
+	assert.NotContains(t, lErr.Skipped(), "Log")
+	assert.ElementsMatch(t, []string{"Log", "DB", "Cache"}, recorder.phase("setup"), "Expected dependents of failed services are not set up")
+}
+
+func (s *BootstrapSuit) TestLifecycleErrorSkipsQueuedServices() {
+	t := s.T()
+	ctx := context.TODO()
+	for _, numOfConcurrencies := range []int{0, gobs.DEFAULT_MAX_CONCURRENT} {
+		recorder := &callRecorder{}
+		bs := gobs.NewBootstrap(gobs.Config{NumOfConcurrencies: numOfConcurrencies})
+		require.NoError(t, bs.AddDefault(&KeyedService{key: "E1", setupErr: assert.AnError, recorder: recorder}, "E1"))
+		require.NoError(t, bs.AddDefault(&KeyedService{key: "E2", recorder: recorder}, "E2"))
+		require.NoError(t, bs.AddDefault(&KeyedService{key: "E3", recorder: recorder}, "E3"))
+		require.NoError(t, bs.AddDefault(&KeyedService{key: "E4", setupErr: errors.New("queued service ran"), recorder: recorder}, "E4"))
+		require.NoError(t, bs.Init(ctx), "Init expected no error")
+
+		err := bs.Setup(ctx)
+		var lErr *common.LifecycleError
+		require.True(t, errors.As(err, &lErr), "Expected error is LifecycleError")
+		require.Len(t, lErr.Errors, 1, "Expected only failures of running services are reported")
+		assert.NotNil(t, lErr.Find("E1"))
+		assert.Equal(t, []string{"E1"}, recorder.phase("setup"), "Expected queued services are not set up after E1 failed")
+	}
+}
+
+func (s *BootstrapSuit) TestLifecycleErrorOnStop() {
+	t := s.T()
+	ctx := context.TODO()
+	for _, numOfConcurrencies := range []int{0, gobs.DEFAULT_MAX_CONCURRENT} {
+		recorder := &callRecorder{}
+		bs := gobs.NewBootstrap(gobs.Config{NumOfConcurrencies: numOfConcurrencies})
+		require.NoError(t, bs.AddDefault(&KeyedService{key: "E4", recorder: recorder}, "E4"))
+		require.NoError(t, bs.AddDefault(&KeyedService{key: "E3", recorder: recorder, stopErr: errStopFailed}, "E3"))
+		require.NoError(t, bs.AddDefault(&KeyedService{key: "E2", recorder: recorder, stopErr: errStopFailed, deps: []string{"E4"}}, "E2"))
+		require.NoError(t, bs.AddDefault(&KeyedService{key: "E1", recorder: recorder, deps: []string{"E2", "E3"}}, "E1"))
+		require.NoError(t, bs.Init(ctx), "Init expected no error")
+		require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+		require.NoError(t, bs.Start(ctx), "Start expected no error")
+
+		err := bs.Stop(ctx)
+		stopped := recorder.phase("stop")
+		var lErr *common.LifecycleError
+		require.True(t, errors.As(err, &lErr), "Expected error is LifecycleError")
+		require.Len(t, lErr.Errors, 2, "Expected all failed services are reported")
+		assert.NotNil(t, lErr.Find("E2"))
+		assert.NotNil(t, lErr.Find("E3"))
+		assert.ElementsMatch(t, []string{"E1", "E2", "E3", "E4"}, stopped, "Expected all services are stopped")
+		assert.Equal(t, "E1", stopped[0])
+		assert.Less(t, indexOf(stopped, "E2"), indexOf(stopped, "E4"), "Expected E4 is stopped after E2 failed")
+	}
+}
+
+func indexOf(list []string, key string) int {
+	for i, k := range list {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,166 @@
+package gobs_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/common"
+	"github.com/xarest/gobs/utils"
+)
+
+type ModuleDB struct {
+	name     string
+	recorder *callRecorder
+}
+
+func (s *ModuleDB) Start(ctx context.Context) error {
+	s.recorder.record("start " + s.name)
+	return nil
+}
+
+func (s *ModuleDB) Stop(ctx context.Context) error {
+	s.recorder.record("stop " + s.name)
+	return nil
+}
+
+type ModuleRepo struct {
+	DB       *ModuleDB
+	recorder *callRecorder
+}
+
+func (s *ModuleRepo) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{
+		Deps: gobs.Dependencies{new(ModuleDB)},
+	}, nil
+}
+
+func (s *ModuleRepo) Setup(ctx context.Context, deps ...gobs.IService) error {
+	return gobs.Dependencies(deps).Assign(&s.DB)
+}
+
+func (s *ModuleRepo) Start(ctx context.Context) error {
+	s.recorder.record("start repo")
+	return nil
+}
+
+func (s *ModuleRepo) Stop(ctx context.Context) error {
+	s.recorder.record("stop repo")
+	return nil
+}
+
+type ModuleAudit struct {
+	DB *ModuleDB
+}
+
+func (s *ModuleAudit) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{
+		Deps: gobs.Dependencies{new(ModuleDB)},
+	}, nil
+}
+
+func (s *ModuleAudit) Setup(ctx context.Context, deps ...gobs.IService) error {
+	return gobs.Dependencies(deps).Assign(&s.DB)
+}
+
+type ModuleAPI struct {
+	DB       *ModuleDB
+	Repo     *ModuleRepo
+	recorder *callRecorder
+}
+
+func (s *ModuleAPI) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{
+		Deps: gobs.Dependencies{new(ModuleDB)},
+		ExtraDeps: []gobs.CustomService{
+			{Service: new(ModuleRepo), Name: "billing::" + utils.DefaultServiceName(ModuleRepo{})},
+		},
+	}, nil
+}
+
+func (s *ModuleAPI) Setup(ctx context.Context, deps ...gobs.IService) error {
+	return gobs.Dependencies(deps).Assign(&s.DB, &s.Repo)
+}
+
+func (s *ModuleAPI) Stop(ctx context.Context) error {
+	s.recorder.record("stop api")
+	return nil
+}
+
+func (s *BootstrapSuit) TestModule() {
+	t := s.T()
+	ctx := context.TODO()
+	recorder := &callRecorder{}
+	globalDB := &ModuleDB{name: "global", recorder: recorder}
+	billingDB := &ModuleDB{name: "billing", recorder: recorder}
+	repo := &ModuleRepo{recorder: recorder}
+	audit := &ModuleAudit{}
+	api := &ModuleAPI{recorder: recorder}
+
+	bs := gobs.NewBootstrap(gobs.Config{NumOfConcurrencies: 0})
+	require.NoError(t, bs.AddDefault(globalDB), "AddDefault expected no error")
+	require.NoError(t, bs.AddDefault(api), "AddDefault expected no error")
+	require.NoError(t, bs.AddModule(&gobs.Module{
+		Name:     "billing",
+		Services: []gobs.IService{repo, billingDB},
+		Modules: []*gobs.Module{
+			{Name: "audit", Services: []gobs.IService{audit}},
+		},
+	}), "AddModule expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+
+	assert.Same(t, billingDB, repo.DB, "Expected dependency is resolved within the module first")
+	assert.Same(t, billingDB, audit.DB, "Expected dependency is resolved within the parent module")
+	assert.Same(t, globalDB, api.DB, "Expected dependency out of modules is resolved globally")
+	assert.Same(t, repo, api.Repo, "Expected services out of modules may depend on services of modules")
+
+	keyRepo := "billing::" + utils.DefaultServiceName(ModuleRepo{})
+	keyAudit := "billing::audit::" + utils.DefaultServiceName(ModuleAudit{})
+	_, ok := gobs.GetService(bs, ModuleAudit{}, keyAudit)
+	assert.True(t, ok, "Expected key of nested module is namespaced")
+	assert.Equal(t, "billing::audit::test_test.ModuleAudit", utils.CompactName(keyAudit))
+
+	g, err := bs.Graph()
+	require.NoError(t, err, "Graph expected no error")
+	modules := map[string]string{}
+	for _, node := range g.Nodes {
+		modules[node.Key] = node.Module
+	}
+	assert.Equal(t, "billing", modules[keyRepo])
+	assert.Equal(t, "billing::audit", modules[keyAudit])
+	assert.Contains(t, g.DOT(), `label="billing";`)
+	assert.Contains(t, g.Mermaid(), `["billing::audit"]`)
+
+	require.NoError(t, bs.Start(ctx), "Start expected no error")
+	recorder.events = nil
+	require.NoError(t, bs.StopModule(ctx, "billing"), "StopModule expected no error")
+	assert.ElementsMatch(t, []string{"stop api", "stop repo", "stop billing"}, recorder.events,
+		"Expected services of the module and their dependents are stopped")
+	assert.Less(t, indexOf(recorder.events, "stop api"), indexOf(recorder.events, "stop repo"))
+	assert.Less(t, indexOf(recorder.events, "stop repo"), indexOf(recorder.events, "stop billing"))
+
+	recorder.events = nil
+	api.Repo = nil
+	require.NoError(t, bs.StartModule(ctx, "billing"), "StartModule expected no error")
+	assert.Equal(t, []string{"start billing", "start repo"}, recorder.events, "Expected services of the module are started again")
+	assert.Same(t, repo, api.Repo, "Expected dependents stopped by StopModule are set up again")
+
+	recorder.events = nil
+	require.NoError(t, bs.Stop(ctx), "Stop expected no error")
+	assert.Contains(t, recorder.events, "stop repo", "Expected restarted services are stopped")
+	assert.Contains(t, recorder.events, "stop api", "Expected dependents stopped by StopModule are restarted")
+	assert.Less(t, indexOf(recorder.events, "stop api"), indexOf(recorder.events, "stop repo"))
+
+	err = bs.StopModule(ctx, "unknown")
+	assert.True(t, errors.Is(err, common.ErrorServiceNotFound), "Expected error for unknown module, got %v", err)
+}
+
+func (s *BootstrapSuit) TestModuleInvalidName() {
+	t := s.T()
+	bs := gobs.NewBootstrap()
+	err := bs.AddModule(&gobs.Module{Name: "a::b"})
+	assert.True(t, errors.Is(err, common.ErrorInvalidType), "Expected error for invalid name, got %v", err)
+}
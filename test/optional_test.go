@@ -0,0 +1,100 @@
+package gobs_test
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+)
+
+type OptionalCache struct{}
+
+type OptionalRequired struct{}
+
+type OptionalConsumer struct {
+	Required *OptionalRequired
+	Cache    *OptionalCache
+	Keyed    *OptionalCache
+}
+
+func (s *OptionalConsumer) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{
+		Deps: gobs.Dependencies{new(OptionalRequired), gobs.Optional(new(OptionalCache))},
+		ExtraDeps: []gobs.CustomService{
+			{Service: new(OptionalCache), Name: "C1", Optional: true},
+		},
+	}, nil
+}
+
+func (s *OptionalConsumer) Setup(ctx context.Context, deps ...gobs.IService) error {
+	return gobs.Dependencies(deps).Assign(&s.Required, &s.Cache, &s.Keyed)
+}
+
+func (s *BootstrapSuit) TestOptionalDependencyMissing() {
+	t := s.T()
+	ctx := context.TODO()
+	consumer := &OptionalConsumer{Keyed: &OptionalCache{}}
+	keyed := consumer.Keyed
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(consumer), "AddDefault expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+
+	assert.NotNil(t, consumer.Required, "Expected required dependency is created")
+	assert.Nil(t, consumer.Cache, "Expected missing optional dependency is nil")
+	assert.Same(t, keyed, consumer.Keyed, "Expected target of missing optional dependency is untouched")
+
+	_, ok := gobs.GetService(bs, OptionalCache{}, "")
+	assert.False(t, ok, "Expected optional dependency is not created")
+	g, err := bs.Graph()
+	require.NoError(t, err, "Graph expected no error")
+	assert.Len(t, g.Nodes, 2)
+	assert.Len(t, g.Edges, 1)
+}
+
+func (s *BootstrapSuit) TestOptionalDependencyRegistered() {
+	t := s.T()
+	ctx := context.TODO()
+	consumer := &OptionalConsumer{}
+	cache, keyed := &OptionalCache{}, &OptionalCache{}
+	bs := gobs.NewBootstrap(gobs.Config{NumOfConcurrencies: 0})
+	require.NoError(t, bs.AddDefault(consumer), "AddDefault expected no error")
+	require.NoError(t, bs.AddDefault(cache), "AddDefault expected no error")
+	require.NoError(t, bs.AddDefault(keyed, "C1"), "AddDefault expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+
+	assert.Same(t, cache, consumer.Cache, "Expected registered optional dependency is assigned")
+	assert.Same(t, keyed, consumer.Keyed, "Expected registered optional dependency is assigned")
+	g, err := bs.Graph()
+	require.NoError(t, err, "Graph expected no error")
+	assert.Len(t, g.Edges, 3)
+}
+
+type OptionalExtraConsumer struct {
+	Cache *OptionalCache
+}
+
+func (s *OptionalExtraConsumer) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{
+		ExtraDeps: []gobs.CustomService{{Service: gobs.Optional(new(OptionalCache))}},
+	}, nil
+}
+
+func (s *OptionalExtraConsumer) Setup(ctx context.Context, deps ...gobs.IService) error {
+	return gobs.Dependencies(deps).Assign(&s.Cache)
+}
+
+func (s *BootstrapSuit) TestOptionalExtraDependency() {
+	t := s.T()
+	ctx := context.TODO()
+	consumer := &OptionalExtraConsumer{}
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(consumer), "AddDefault expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+	assert.Nil(t, consumer.Cache, "Expected missing optional dependency is nil")
+	_, ok := gobs.GetService(bs, OptionalCache{}, "")
+	assert.False(t, ok, "Expected optional dependency is not created")
+}
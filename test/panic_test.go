@@ -0,0 +1,91 @@
+package gobs_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/common"
+	"github.com/xarest/gobs/utils"
+)
+
+type PanicService struct {
+	stopped bool
+}
+
+func (s *PanicService) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{
+		Deps: gobs.Dependencies{new(PanicDependency)},
+		AsyncMode: map[common.ServiceStatus]bool{
+			common.StatusSetup: true,
+		},
+	}, nil
+}
+
+func (s *PanicService) Setup(ctx context.Context, deps ...gobs.IService) error {
+	panic(assert.AnError)
+}
+
+type PanicDependency struct {
+	stopped bool
+}
+
+func (s *PanicDependency) Stop(ctx context.Context) error {
+	s.stopped = true
+	return nil
+}
+
+type PanicServer struct {
+	ready bool
+}
+
+func (s *PanicServer) StartServer(ctx context.Context, onReady func(err error)) error {
+	if s.ready {
+		onReady(nil)
+	}
+	panic("server crashed")
+}
+
+func (s *BootstrapSuit) TestPanicOnSetup() {
+	t := s.T()
+	ctx := context.TODO()
+	bs := gobs.NewBootstrap(gobs.Config{
+		NumOfConcurrencies: gobs.DEFAULT_MAX_CONCURRENT,
+		EnableRollback:     true,
+	})
+	require.NoError(t, bs.AddDefault(new(PanicService)), "AddDefault expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+
+	err := bs.Setup(ctx)
+	require.ErrorIs(t, err, common.ErrorPanic, "Setup expected panic error")
+	assert.ErrorIs(t, err, assert.AnError, "Expected panic value is unwrapped")
+	var panicErr *common.PanicError
+	require.True(t, errors.As(err, &panicErr), "Expected error is PanicError")
+	assert.Equal(t, utils.DefaultServiceName(PanicService{}), panicErr.Service)
+	assert.Equal(t, common.StatusSetup, panicErr.Phase)
+	assert.Contains(t, string(panicErr.Stack), "PanicService", "Expected stack trace of the panic")
+
+	dep, ok := gobs.GetService(bs, PanicDependency{}, "")
+	require.True(t, ok, "Expected GetService returns PanicDependency")
+	assert.True(t, dep.stopped, "Expected dependency is rolled back")
+}
+
+func (s *BootstrapSuit) TestPanicOnStartServer() {
+	t := s.T()
+	ctx := context.TODO()
+	for _, ready := range []bool{false, true} {
+		bs := gobs.NewBootstrap(gobs.Config{NumOfConcurrencies: gobs.DEFAULT_MAX_CONCURRENT})
+		require.NoError(t, bs.AddDefault(&PanicServer{ready: ready}), "AddDefault expected no error")
+		require.NoError(t, bs.Init(ctx), "Init expected no error")
+		require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+		err := bs.Start(ctx)
+		if ready {
+			assert.NoError(t, err, "Expected panic after ready does not fail Start")
+		} else {
+			assert.ErrorIs(t, err, common.ErrorPanic, "Start expected panic error")
+		}
+		require.NoError(t, bs.Stop(ctx), "Stop expected no error")
+	}
+}
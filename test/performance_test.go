@@ -3,9 +3,16 @@ package gobs_test
 import (
 	"context"
 	"fmt"
+	"runtime"
+	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/common"
+	"github.com/xarest/gobs/logger"
+	"github.com/xarest/gobs/scheduler"
+	"github.com/xarest/gobs/types"
 )
 
 var numOfServices = 0
@@ -65,3 +72,124 @@ func NewSampleService(numOfDeps, level int) gobs.IService {
 		numOfDeps: numOfDeps,
 	}
 }
+
+// BenchTask is a task without work, so that benchmarks measure the scheduler only.
+type BenchTask struct {
+	name      string
+	isAsync   bool
+	following []types.ITask
+	followers []types.ITask
+}
+
+func (t *BenchTask) Run(ctx context.Context, status common.ServiceStatus) error { return nil }
+func (t *BenchTask) IsRunAsync(status common.ServiceStatus) bool                { return t.isAsync }
+func (t *BenchTask) Name() string                                               { return t.name }
+func (t *BenchTask) DependOn(status common.ServiceStatus) []types.ITask         { return t.following }
+func (t *BenchTask) Followers(status common.ServiceStatus) []types.ITask        { return t.followers }
+
+var _ types.ITask = (*BenchTask)(nil)
+
+// newBenchTasks builds layers of width tasks, each task depends on one or two tasks of the previous layer.
+func newBenchTasks(numOfTasks, width int, isAsync bool) []types.ITask {
+	tasks := make([]types.ITask, numOfTasks)
+	for i := range tasks {
+		tasks[i] = &BenchTask{name: fmt.Sprintf("Task-%d", i), isAsync: isAsync}
+	}
+	link := func(i, j int) {
+		task, dep := tasks[i].(*BenchTask), tasks[j].(*BenchTask)
+		task.following = append(task.following, dep)
+		dep.followers = append(dep.followers, task)
+	}
+	for i := width; i < numOfTasks; i++ {
+		link(i, i-width)
+		if i%width > 0 {
+			link(i, i-width-1)
+		}
+	}
+	return tasks
+}
+
+func (s *SchedulerSuit) TestSchedulerScale() {
+	t := s.T()
+	ctx := context.TODO()
+	for _, numOfConcurrencies := range []int{0, 8, gobs.DEFAULT_MAX_CONCURRENT} {
+		for _, isAsync := range []bool{false, true} {
+			tasks := newBenchTasks(10000, 100, isAsync)
+			sched := scheduler.NewScheduler(ctx, logger.NewLog(nil), tasks, common.StatusSetup, numOfConcurrencies)
+			require.NoError(t, sched.Run(ctx), "Run expected no error")
+			results, err := sched.Release()
+			require.NoError(t, err, "Release expected no error")
+			require.Len(t, results, len(tasks))
+
+			finished := make(map[string]bool, len(results))
+			for _, task := range results {
+				for _, dep := range task.DependOn(common.StatusSetup) {
+					assert.True(t, finished[dep.Name()], "Expected %s finishes before %s", dep.Name(), task.Name())
+				}
+				finished[task.Name()] = true
+			}
+		}
+	}
+}
+
+func BenchmarkScheduler(b *testing.B) {
+	ctx := context.Background()
+	cases := []struct {
+		name               string
+		isAsync            bool
+		numOfConcurrencies int
+	}{
+		{"Sequential", false, 0},
+		{"Sync", false, gobs.DEFAULT_MAX_CONCURRENT},
+		{"Async", true, gobs.DEFAULT_MAX_CONCURRENT},
+		{"AsyncLimited", true, 8},
+	}
+	for _, numOfTasks := range []int{1000, 10000} {
+		for _, bc := range cases {
+			b.Run(fmt.Sprintf("%s-%d", bc.name, numOfTasks), func(b *testing.B) {
+				tasks := newBenchTasks(numOfTasks, 100, bc.isAsync)
+				var before, after runtime.MemStats
+				b.ReportAllocs()
+				runtime.ReadMemStats(&before)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					sched := scheduler.NewScheduler(ctx, logger.NewLog(nil), tasks, common.StatusSetup, bc.numOfConcurrencies)
+					if err := sched.Run(ctx); err != nil {
+						b.Fatal(err)
+					}
+					sched.Release()
+				}
+				b.StopTimer()
+				runtime.ReadMemStats(&after)
+				total := float64(b.N * numOfTasks)
+				b.ReportMetric(float64(b.Elapsed().Nanoseconds())/total, "ns/task")
+				b.ReportMetric(float64(after.Mallocs-before.Mallocs)/total, "allocs/task")
+			})
+		}
+	}
+}
+
+func BenchmarkBootstrap(b *testing.B) {
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		bs := gobs.NewBootstrap(gobs.Config{
+			NumOfConcurrencies: gobs.DEFAULT_MAX_CONCURRENT,
+		})
+		if err := bs.AddDefault(NewSampleAsyncService(10, 3)); err != nil {
+			b.Fatal(err)
+		}
+		if err := bs.Init(ctx); err != nil {
+			b.Fatal(err)
+		}
+		if err := bs.Setup(ctx); err != nil {
+			b.Fatal(err)
+		}
+		if err := bs.Start(ctx); err != nil {
+			b.Fatal(err)
+		}
+		if err := bs.Stop(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package gobs_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/common"
+	"github.com/xarest/gobs/utils"
+)
+
+func planOrder(plan *gobs.Plan) []int {
+	order := make([]int, 0, len(plan.Order))
+	for _, key := range plan.Order {
+		name := utils.CompactName(key)
+		n, _ := strconv.Atoi(name[strings.LastIndex(name, ".S")+2:])
+		order = append(order, n)
+	}
+	return order
+}
+
+func (s *BootstrapSuit) TestPlanSequential() {
+	t := s.T()
+	setupOrder = []int{}
+	ctx := context.TODO()
+	bs := gobs.NewBootstrap(gobs.Config{NumOfConcurrencies: 0})
+	require.NoError(t, bs.AddDefault(new(S1)), "AddDefault expected no error")
+	_, err := bs.Plan(common.StatusSetup)
+	require.Error(t, err, "Plan expected error before Init")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+
+	plan, err := bs.Plan(common.StatusSetup)
+	require.NoError(t, err, "Plan expected no error")
+	assert.True(t, plan.Sequential)
+	assert.Empty(t, setupOrder, "Expected no service runs when planning")
+
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+	assert.Equal(t, setupOrder, planOrder(plan), "Expected plan matches the order services are setup")
+}
+
+func (s *BootstrapSuit) TestPlanWaves() {
+	t := s.T()
+	ctx := context.TODO()
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "API", deps: []string{"DB", "Cache"}}, "API"))
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "DB"}, "DB"))
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "Cache"}, "Cache"))
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "Worker", deps: []string{"DB"}}, "Worker"))
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+
+	plan, err := bs.Plan(common.StatusSetup)
+	require.NoError(t, err, "Plan expected no error")
+	assert.False(t, plan.Sequential)
+	require.Len(t, plan.Waves, 2)
+	keys := func(wave gobs.PlanWave) []string {
+		var res []string
+		for _, step := range wave.Steps {
+			res = append(res, step.Key)
+		}
+		return res
+	}
+	assert.Equal(t, []string{"DB", "Cache"}, keys(plan.Waves[0]))
+	assert.Equal(t, []string{"Worker", "API"}, keys(plan.Waves[1]), "Expected services in order they become ready")
+	assert.Equal(t, []string{"DB", "Cache", "Worker", "API"}, plan.Order)
+
+	plan, err = bs.Plan(common.StatusStop)
+	require.NoError(t, err, "Plan expected no error")
+	require.Len(t, plan.Waves, 2)
+	assert.Equal(t, []string{"API", "Worker"}, keys(plan.Waves[0]), "Expected dependents stop first")
+	assert.Equal(t, []string{"Cache", "DB"}, keys(plan.Waves[1]))
+	assert.Contains(t, plan.String(), "wave 1: API (sync), Worker (sync)")
+
+	_, err = bs.Plan(common.StatusUninitialized)
+	assert.ErrorIs(t, err, common.ErrorInvalidType)
+}
+
+func (s *BootstrapSuit) TestPlanConcurrent() {
+	t := s.T()
+	ctx := context.TODO()
+	recorder := &priorityRecorder{order: map[common.ServiceStatus][]string{}}
+	bs := gobs.NewBootstrap(gobs.Config{NumOfConcurrencies: 1})
+	require.NoError(t, bs.AddDefault(&PriorityDB{PriorityService{name: "db", async: true, recorder: recorder}}))
+	require.NoError(t, bs.AddDefault(&PriorityService{name: "warmer-1", async: true, recorder: recorder}, "warmer-1"))
+	require.NoError(t, bs.AddDefault(&PriorityService{name: "warmer-2", async: true, recorder: recorder}, "warmer-2"))
+	require.NoError(t, bs.AddDefault(&PriorityService{
+		name: "api", priority: 100, async: true, deps: gobs.Dependencies{new(PriorityDB)}, recorder: recorder,
+	}, "api"))
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+
+	plan, err := bs.Plan(common.StatusStart)
+	require.NoError(t, err, "Plan expected no error")
+	assert.False(t, plan.Sequential)
+	keys := make([]string, 0, len(plan.Order))
+	for _, key := range plan.Order {
+		keys = append(keys, utils.CompactName(key))
+	}
+	assert.Equal(t, []string{"test_test.PriorityDB", "api", "warmer-1", "warmer-2"}, keys,
+		"Expected plan follows the priorities within the concurrency limit")
+
+	require.NoError(t, bs.Start(ctx), "Start expected no error")
+	assert.Equal(t, []string{"db", "api", "warmer-1", "warmer-2"}, recorder.order[common.StatusStart],
+		"Expected plan matches the order services are started")
+	require.NoError(t, bs.Stop(ctx), "Stop expected no error")
+}
@@ -0,0 +1,112 @@
+package gobs_test
+
+import (
+	"context"
+	"sync"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/common"
+)
+
+type priorityRecorder struct {
+	mutex sync.Mutex
+	order map[common.ServiceStatus][]string
+}
+
+func (r *priorityRecorder) record(ss common.ServiceStatus, name string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.order[ss] = append(r.order[ss], name)
+}
+
+type PriorityService struct {
+	name       string
+	priority   int
+	priorities map[common.ServiceStatus]int
+	deps       gobs.Dependencies
+	async      bool
+	recorder   *priorityRecorder
+}
+
+func (s *PriorityService) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{
+		Deps:       s.deps,
+		Priority:   s.priority,
+		Priorities: s.priorities,
+		AsyncMode:  map[common.ServiceStatus]bool{common.StatusStart: s.async},
+	}, nil
+}
+
+func (s *PriorityService) Start(ctx context.Context) error {
+	s.recorder.record(common.StatusStart, s.name)
+	return nil
+}
+
+func (s *PriorityService) Stop(ctx context.Context) error {
+	s.recorder.record(common.StatusStop, s.name)
+	return nil
+}
+
+type PriorityDB struct {
+	PriorityService
+}
+
+func (s *BootstrapSuit) TestPriority() {
+	for _, cfg := range []gobs.Config{{NumOfConcurrencies: gobs.DEFAULT_MAX_CONCURRENT}, {NumOfConcurrencies: 0}} {
+		t := s.T()
+		ctx := context.TODO()
+		recorder := &priorityRecorder{order: map[common.ServiceStatus][]string{}}
+		db := &PriorityDB{PriorityService{name: "db", recorder: recorder}}
+		bs := gobs.NewBootstrap(cfg)
+		require.NoError(t, bs.AddDefault(&PriorityService{name: "warmer", recorder: recorder}, "warmer"))
+		require.NoError(t, bs.AddDefault(&PriorityService{
+			name: "metrics", priority: 10, recorder: recorder,
+			priorities: map[common.ServiceStatus]int{common.StatusStop: -10},
+		}, "metrics"))
+		require.NoError(t, bs.AddDefault(&PriorityService{
+			name: "api", priority: 100, deps: gobs.Dependencies{new(PriorityDB)}, recorder: recorder,
+		}, "api"))
+		require.NoError(t, bs.AddDefault(&PriorityService{name: "tracing", priority: 5, recorder: recorder}, "tracing"))
+		require.NoError(t, bs.AddDefault(db), "AddDefault expected no error")
+		require.NoError(t, bs.Init(ctx), "Init expected no error")
+		require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+		require.NoError(t, bs.Start(ctx), "Start expected no error")
+		require.NoError(t, bs.Stop(ctx), "Stop expected no error")
+
+		started := recorder.order[common.StatusStart]
+		require.Len(t, started, 5)
+		assert.Less(t, indexOf(started, "metrics"), indexOf(started, "tracing"), "Expected higher priorities start first")
+		assert.Less(t, indexOf(started, "tracing"), indexOf(started, "warmer"), "Expected higher priorities start first")
+		assert.Less(t, indexOf(started, "db"), indexOf(started, "api"), "Expected dependencies run first whatever the priority")
+		if cfg.NumOfConcurrencies != 0 {
+			assert.Equal(t, "metrics", started[0], "Expected highest priority among ready services starts first")
+		}
+
+		stopped := recorder.order[common.StatusStop]
+		require.Len(t, stopped, 5)
+		assert.Equal(t, "api", stopped[0], "Expected highest priority stops first")
+		assert.Less(t, indexOf(stopped, "warmer"), indexOf(stopped, "metrics"), "Expected priority of the phase overrides the default priority")
+	}
+}
+
+func (s *BootstrapSuit) TestPriorityWithConcurrencyLimit() {
+	t := s.T()
+	ctx := context.TODO()
+	recorder := &priorityRecorder{order: map[common.ServiceStatus][]string{}}
+	bs := gobs.NewBootstrap(gobs.Config{NumOfConcurrencies: 1})
+	require.NoError(t, bs.AddDefault(&PriorityDB{PriorityService{name: "db", async: true, recorder: recorder}}))
+	require.NoError(t, bs.AddDefault(&PriorityService{name: "warmer-1", async: true, recorder: recorder}, "warmer-1"))
+	require.NoError(t, bs.AddDefault(&PriorityService{name: "warmer-2", async: true, recorder: recorder}, "warmer-2"))
+	require.NoError(t, bs.AddDefault(&PriorityService{
+		name: "api", priority: 100, async: true, deps: gobs.Dependencies{new(PriorityDB)}, recorder: recorder,
+	}, "api"))
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+	require.NoError(t, bs.Start(ctx), "Start expected no error")
+
+	assert.Equal(t, []string{"db", "api", "warmer-1", "warmer-2"}, recorder.order[common.StatusStart],
+		"Expected a service ready later with a higher priority runs before queued services")
+	require.NoError(t, bs.Stop(ctx), "Stop expected no error")
+}
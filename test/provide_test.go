@@ -0,0 +1,149 @@
+package gobs_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/common"
+)
+
+type ProvidedDB struct {
+	ready bool
+}
+
+func (s *ProvidedDB) Setup(ctx context.Context, deps ...gobs.IService) error {
+	s.ready = true
+	return nil
+}
+
+type ProvidedRepo struct {
+	DB      *ProvidedDB
+	Storage Storage
+	started bool
+	stopped bool
+}
+
+func (s *ProvidedRepo) Start(ctx context.Context) error {
+	s.started = true
+	return nil
+}
+
+func (s *ProvidedRepo) Stop(ctx context.Context) error {
+	s.stopped = true
+	return nil
+}
+
+type ProvidedHandler struct {
+	Repo *ProvidedRepo
+}
+
+func (s *ProvidedHandler) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{
+		Deps: gobs.Dependencies{new(ProvidedRepo)},
+	}, nil
+}
+
+func (s *ProvidedHandler) Setup(ctx context.Context, deps ...gobs.IService) error {
+	return gobs.Dependencies(deps).Assign(&s.Repo)
+}
+
+func (s *BootstrapSuit) TestProvide() {
+	t := s.T()
+	ctx := context.TODO()
+	handler := &ProvidedHandler{}
+	var dbReady bool
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(handler), "AddDefault expected no error")
+	require.NoError(t, bs.AddDefault(&DiskStorage{}), "AddDefault expected no error")
+	require.NoError(t, bs.Provide(func(ctx context.Context, db *ProvidedDB, storage Storage) (*ProvidedRepo, error) {
+		dbReady = db.ready
+		return &ProvidedRepo{DB: db, Storage: storage}, nil
+	}), "Provide expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+
+	require.NotNil(t, handler.Repo, "Expected provided service is assigned to dependents")
+	assert.True(t, dbReady, "Expected constructor is called after dependencies are setup")
+	assert.NotNil(t, handler.Repo.DB, "Expected pointer parameter is resolved")
+	assert.IsType(t, &DiskStorage{}, handler.Repo.Storage, "Expected interface parameter is resolved")
+	repo, ok := gobs.GetService(bs, ProvidedRepo{}, "")
+	assert.True(t, ok, "Expected provided service is registered with the default key of its type")
+	assert.Same(t, handler.Repo, repo)
+
+	require.NoError(t, bs.Start(ctx), "Start expected no error")
+	assert.True(t, handler.Repo.started, "Expected provided service is started")
+	require.NoError(t, bs.Stop(ctx), "Stop expected no error")
+	assert.True(t, handler.Repo.stopped, "Expected provided service is stopped")
+}
+
+func (s *BootstrapSuit) TestProvideWithName() {
+	t := s.T()
+	ctx := context.TODO()
+	bs := gobs.NewBootstrap(gobs.Config{NumOfConcurrencies: 0})
+	require.NoError(t, bs.Provide(func() Storage {
+		return &MemoryStorage{}
+	}, gobs.WithName("memory")), "Provide expected no error")
+	consumer := &StorageConsumer{}
+	require.NoError(t, bs.AddDefault(consumer), "AddDefault expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+
+	g, err := bs.Graph()
+	require.NoError(t, err, "Graph expected no error")
+	var found bool
+	for _, node := range g.Nodes {
+		if node.Key == "memory" {
+			found = true
+			assert.Equal(t, "gobs_test.Storage", node.Type, "Expected result type of the constructor before Setup")
+		}
+	}
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+	assert.IsType(t, &MemoryStorage{}, consumer.Storage, "Expected interface is resolved to provided service")
+	assert.True(t, found, "Expected provided service is registered with the name")
+}
+
+func (s *BootstrapSuit) TestProvideError() {
+	t := s.T()
+	ctx := context.TODO()
+	errCtor := errors.New("cannot connect")
+	handler := &ProvidedHandler{}
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(handler), "AddDefault expected no error")
+	require.NoError(t, bs.Provide(func(db *ProvidedDB) (*ProvidedRepo, error) {
+		return nil, errCtor
+	}), "Provide expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	err := bs.Setup(ctx)
+	assert.ErrorIs(t, err, errCtor)
+	assert.Nil(t, handler.Repo, "Expected dependents are not setup")
+}
+
+func (s *BootstrapSuit) TestProvideInvalidConstructor() {
+	t := s.T()
+	bs := gobs.NewBootstrap()
+	for _, ctor := range []any{
+		&ProvidedDB{},
+		func() {},
+		func() error { return nil },
+		func() (*ProvidedDB, *ProvidedRepo) { return nil, nil },
+		func(name string) *ProvidedDB { return nil },
+		func() map[string]int { return nil },
+		func() *struct{ Name string } { return nil },
+	} {
+		err := bs.Provide(ctor)
+		assert.True(t, errors.Is(err, common.ErrorInvalidType), "Expected error for constructor %T, got %v", ctor, err)
+	}
+}
+
+func (s *BootstrapSuit) TestProvideDuplicate() {
+	t := s.T()
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.Provide(func() *ProvidedDB { return &ProvidedDB{} }), "Provide expected no error")
+	err := bs.Provide(func() *ProvidedDB { return &ProvidedDB{} })
+	assert.ErrorIs(t, err, common.ErrorServiceExists, "Expected error for duplicated key")
+	require.NoError(t, bs.Provide(func() map[string]int { return nil }, gobs.WithName("settings")), "Provide expected no error")
+	err = bs.Provide(func() *ProvidedDB { return &ProvidedDB{} }, gobs.WithName("settings"))
+	assert.ErrorIs(t, err, common.ErrorServiceExists, "Expected error for duplicated name")
+}
@@ -0,0 +1,76 @@
+package gobs_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/common"
+)
+
+type ProfiledService struct {
+	delay time.Duration
+	deps  []string
+}
+
+func (s *ProfiledService) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	cfg := &gobs.ServiceLifeCycle{
+		AsyncMode: map[common.ServiceStatus]bool{common.StatusSetup: true},
+	}
+	for _, dep := range s.deps {
+		cfg.ExtraDeps = append(cfg.ExtraDeps, gobs.CustomService{Name: dep})
+	}
+	return cfg, nil
+}
+
+func (s *ProfiledService) Setup(ctx context.Context, deps ...gobs.IService) error {
+	time.Sleep(s.delay)
+	return nil
+}
+
+func (s *BootstrapSuit) TestReport() {
+	t := s.T()
+	ctx := context.TODO()
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(&ProfiledService{delay: 20 * time.Millisecond}, "db"))
+	require.NoError(t, bs.AddDefault(&ProfiledService{delay: 40 * time.Millisecond, deps: []string{"db"}}, "repo"))
+	require.NoError(t, bs.AddDefault(&ProfiledService{delay: 10 * time.Millisecond}, "cache"))
+	require.NoError(t, bs.AddDefault(&ProfiledService{deps: []string{"repo", "cache"}}, "api"))
+	assert.Empty(t, bs.Report().Phases, "Expected no report before running phases")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+
+	report := bs.Report()
+	require.Len(t, report.Phases, 2)
+	assert.Nil(t, report.Phase(common.StatusStart), "Expected no report of phases which did not run")
+	setup := report.Phase(common.StatusSetup)
+	require.NotNil(t, setup)
+	require.Len(t, setup.Services, 4)
+
+	keys := make([]string, 0, len(setup.CriticalPath))
+	for _, p := range setup.CriticalPath {
+		keys = append(keys, p.Key)
+	}
+	assert.Equal(t, []string{"db", "repo", "api"}, keys, "Expected critical path goes through the slowest chain")
+	assert.GreaterOrEqual(t, setup.CriticalPathTime(), 60*time.Millisecond)
+	assert.GreaterOrEqual(t, setup.TotalWork, 70*time.Millisecond, "Expected total work sums all services")
+	assert.GreaterOrEqual(t, setup.WallTime, 60*time.Millisecond)
+	assert.Less(t, setup.WallTime, setup.TotalWork, "Expected services ran concurrently")
+
+	top := setup.Top(2)
+	require.Len(t, top, 2)
+	assert.Equal(t, "repo", top[0].Key)
+	assert.Equal(t, "db", top[1].Key)
+	assert.Len(t, setup.Slowest, 4)
+
+	for _, p := range setup.Services {
+		if p.Key == "repo" {
+			assert.GreaterOrEqual(t, p.Waiting, 20*time.Millisecond, "Expected time waiting on dependencies is recorded")
+			assert.GreaterOrEqual(t, p.Exec, 40*time.Millisecond, "Expected execution time is recorded")
+			assert.GreaterOrEqual(t, p.Queued, time.Duration(0))
+		}
+	}
+	assert.Contains(t, report.String(), "critical path")
+}
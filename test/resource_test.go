@@ -0,0 +1,179 @@
+package gobs_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/common"
+)
+
+type resourceCounter struct {
+	running atomic.Int32
+	max     atomic.Int32
+}
+
+func (c *resourceCounter) enter() {
+	n := c.running.Add(1)
+	for {
+		m := c.max.Load()
+		if n <= m || c.max.CompareAndSwap(m, n) {
+			return
+		}
+	}
+}
+
+type ResourceService struct {
+	resources      map[string]int
+	phaseResources map[common.ServiceStatus]map[string]int
+	counter        *resourceCounter
+}
+
+func (s *ResourceService) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{
+		AsyncMode:      map[common.ServiceStatus]bool{common.StatusSetup: true},
+		Resources:      s.resources,
+		PhaseResources: s.phaseResources,
+	}, nil
+}
+
+func (s *ResourceService) Setup(ctx context.Context, deps ...gobs.IService) error {
+	if s.counter != nil {
+		s.counter.enter()
+		defer s.counter.running.Add(-1)
+	}
+	time.Sleep(20 * time.Millisecond)
+	return nil
+}
+
+func (s *BootstrapSuit) TestResourceLimits() {
+	t := s.T()
+	ctx := context.TODO()
+	db, all := &resourceCounter{}, &resourceCounter{}
+	bs := gobs.NewBootstrap(gobs.Config{
+		NumOfConcurrencies: gobs.DEFAULT_MAX_CONCURRENT,
+		ResourceLimits:     map[string]int{"db": 2},
+	})
+	var mutex sync.Mutex
+	waiting := map[string]int{}
+	bs.Subscribe(func(e gobs.Event) {
+		if e.Type == gobs.EventServiceWaiting {
+			mutex.Lock()
+			waiting[e.Resource]++
+			mutex.Unlock()
+		}
+	})
+	for i := 0; i < 5; i++ {
+		require.NoError(t, bs.AddDefault(&ResourceService{resources: map[string]int{"db": 1}, counter: db}, fmt.Sprintf("db-%d", i)))
+	}
+	require.NoError(t, bs.AddDefault(&ResourceService{resources: map[string]int{"db": 2}, counter: db}, "migration"))
+	for i := 0; i < 4; i++ {
+		require.NoError(t, bs.AddDefault(&ResourceService{resources: map[string]int{"cpu": 1}, counter: all}, fmt.Sprintf("other-%d", i)))
+	}
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+
+	assert.LessOrEqual(t, db.max.Load(), int32(2), "Expected at most 2 services using the database run concurrently")
+	assert.Equal(t, int32(4), all.max.Load(), "Expected services without limits run concurrently")
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Greater(t, waiting["db"], 0, "Expected waiting services are notified")
+	assert.Zero(t, waiting["cpu"], "Expected no wait for classes without limit")
+}
+
+func (s *BootstrapSuit) TestResourceWeightExceedsLimit() {
+	t := s.T()
+	ctx := context.TODO()
+	bs := gobs.NewBootstrap(gobs.Config{
+		NumOfConcurrencies: gobs.DEFAULT_MAX_CONCURRENT,
+		ResourceLimits:     map[string]int{"db": 1},
+	})
+	require.NoError(t, bs.AddDefault(&ResourceService{resources: map[string]int{"db": 2}}, "migration"))
+	err := bs.Init(ctx)
+	assert.True(t, errors.Is(err, common.ErrorResourceExceeded), "Expected error for weight over the limit, got %v", err)
+
+	bs = gobs.NewBootstrap(gobs.Config{
+		NumOfConcurrencies: gobs.DEFAULT_MAX_CONCURRENT,
+		ResourceLimits:     map[string]int{"db": 1},
+	})
+	require.NoError(t, bs.AddDefault(&ResourceService{
+		phaseResources: map[common.ServiceStatus]map[string]int{common.StatusStop: {"db": 2}},
+	}, "migration"))
+	err = bs.Init(ctx)
+	assert.True(t, errors.Is(err, common.ErrorResourceExceeded), "Expected error for weight over the limit in any phase, got %v", err)
+}
+
+func (s *BootstrapSuit) TestResourcesOfPhase() {
+	t := s.T()
+	ctx := context.TODO()
+	db := &resourceCounter{}
+	bs := gobs.NewBootstrap(gobs.Config{
+		NumOfConcurrencies: gobs.DEFAULT_MAX_CONCURRENT,
+		ResourceLimits:     map[string]int{"db": 1},
+	})
+	for i := 0; i < 3; i++ {
+		require.NoError(t, bs.AddDefault(&ResourceService{
+			resources:      map[string]int{"db": 1},
+			phaseResources: map[common.ServiceStatus]map[string]int{common.StatusSetup: nil},
+			counter:        db,
+		}, fmt.Sprintf("db-%d", i)))
+	}
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+	assert.Equal(t, int32(3), db.max.Load(), "Expected resources of the phase override the default resources")
+
+	plan, err := bs.Plan(common.StatusStop)
+	require.NoError(t, err, "Plan expected no error")
+	assert.Equal(t, map[string]int{"db": 1}, plan.Waves[0].Steps[0].Resources, "Expected default resources in other phases")
+}
+
+type BlockingResourceService struct {
+	name     string
+	priority int
+	delay    time.Duration
+	recorder *callRecorder
+}
+
+func (s *BlockingResourceService) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	lc := &gobs.ServiceLifeCycle{
+		AsyncMode: map[common.ServiceStatus]bool{common.StatusSetup: true},
+		Priority:  s.priority,
+	}
+	if s.delay > 0 {
+		lc.Resources = map[string]int{"db": 1}
+	}
+	return lc, nil
+}
+
+func (s *BlockingResourceService) Setup(ctx context.Context, deps ...gobs.IService) error {
+	s.recorder.record("start " + s.name)
+	time.Sleep(s.delay)
+	s.recorder.record("end " + s.name)
+	return nil
+}
+
+func (s *BootstrapSuit) TestResourceNoHeadOfLineBlocking() {
+	t := s.T()
+	ctx := context.TODO()
+	recorder := &callRecorder{}
+	bs := gobs.NewBootstrap(gobs.Config{
+		NumOfConcurrencies: 2,
+		ResourceLimits:     map[string]int{"db": 1},
+	})
+	require.NoError(t, bs.AddDefault(&BlockingResourceService{name: "db-1", priority: 2, delay: 100 * time.Millisecond, recorder: recorder}, "db-1"))
+	require.NoError(t, bs.AddDefault(&BlockingResourceService{name: "db-2", priority: 1, delay: 100 * time.Millisecond, recorder: recorder}, "db-2"))
+	require.NoError(t, bs.AddDefault(&BlockingResourceService{name: "cache", recorder: recorder}, "cache"))
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+
+	events := recorder.events
+	assert.Less(t, indexOf(events, "end cache"), indexOf(events, "end db-1"),
+		"Expected a service waiting for resources does not hold a slot of the concurrency limit")
+	assert.Less(t, indexOf(events, "end db-1"), indexOf(events, "start db-2"), "Expected resource limit is respected")
+}
@@ -0,0 +1,159 @@
+package gobs_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/common"
+)
+
+var errFatal = errors.New("fatal")
+
+type FlakyService struct {
+	policy   *gobs.RetryPolicy
+	failures int
+	err      error
+	attempts int
+}
+
+func (s *FlakyService) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{
+		Retries: map[common.ServiceStatus]*gobs.RetryPolicy{
+			common.StatusSetup: s.policy,
+		},
+	}, nil
+}
+
+func (s *FlakyService) Setup(ctx context.Context, deps ...gobs.IService) error {
+	s.attempts++
+	if s.attempts <= s.failures {
+		return s.err
+	}
+	return nil
+}
+
+type logRecorder struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *logRecorder) Log(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+func (l *logRecorder) Contains(substr string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, line := range l.lines {
+		if strings.Contains(line, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *BootstrapSuit) TestRetry() {
+	t := s.T()
+	ctx := context.TODO()
+	logs := &logRecorder{}
+	service := &FlakyService{
+		failures: 2,
+		err:      assert.AnError,
+		policy:   &gobs.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	}
+	bs := gobs.NewBootstrap(gobs.Config{
+		NumOfConcurrencies: gobs.DEFAULT_MAX_CONCURRENT,
+		Logger:             logs.Log,
+	})
+	require.NoError(t, bs.AddDefault(service), "AddDefault expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+	assert.Equal(t, 3, service.attempts)
+	assert.True(t, logs.Contains("(attempt 2/3)"), "Expected attempts are logged")
+
+	service = &FlakyService{
+		failures: 5,
+		err:      assert.AnError,
+		policy:   &gobs.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	}
+	bs = gobs.NewBootstrap(gobs.Config{NumOfConcurrencies: 0})
+	require.NoError(t, bs.AddDefault(service), "AddDefault expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.ErrorIs(t, bs.Setup(ctx), assert.AnError, "Setup expected error")
+	assert.Equal(t, 3, service.attempts)
+}
+
+func (s *BootstrapSuit) TestRetryNotRetryable() {
+	t := s.T()
+	ctx := context.TODO()
+	service := &FlakyService{
+		failures: 1,
+		err:      errFatal,
+		policy: &gobs.RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			Retryable: func(err error) bool {
+				return !errors.Is(err, errFatal)
+			},
+		},
+	}
+	bs := gobs.NewBootstrap(gobs.Config{NumOfConcurrencies: 0})
+	require.NoError(t, bs.AddDefault(service), "AddDefault expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.ErrorIs(t, bs.Setup(ctx), errFatal, "Setup expected error")
+	assert.Equal(t, 1, service.attempts)
+}
+
+func (s *BootstrapSuit) TestRetryInterrupted() {
+	t := s.T()
+	ctx := context.TODO()
+	service := &FlakyService{
+		failures: 5,
+		err:      assert.AnError,
+		policy:   &gobs.RetryPolicy{MaxAttempts: 5, InitialBackoff: 5 * time.Second},
+	}
+	bs := gobs.NewBootstrap(gobs.Config{NumOfConcurrencies: gobs.DEFAULT_MAX_CONCURRENT})
+	require.NoError(t, bs.AddDefault(service), "AddDefault expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+
+	chSetup := make(chan error, 1)
+	go func() {
+		chSetup <- bs.Setup(ctx)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	begin := time.Now()
+	bs.Interrupt(ctx, 0)
+	require.Error(t, <-chSetup, "Setup expected error")
+	assert.Less(t, time.Since(begin), time.Second, "Expected retrying stops on interruption")
+	require.NoError(t, bs.Stop(ctx), "Stop expected no error")
+	assert.Equal(t, 1, service.attempts)
+}
+
+func (s *BootstrapSuit) TestRetryBackoff() {
+	t := s.T()
+	policy := &gobs.RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     3,
+	}
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(1))
+	assert.Equal(t, 300*time.Millisecond, policy.Backoff(2))
+	assert.Equal(t, 900*time.Millisecond, policy.Backoff(3))
+	assert.Equal(t, time.Second, policy.Backoff(4))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 10; i++ {
+		backoff := policy.Backoff(1)
+		assert.GreaterOrEqual(t, backoff, 50*time.Millisecond)
+		assert.LessOrEqual(t, backoff, 150*time.Millisecond)
+	}
+}
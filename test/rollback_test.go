@@ -0,0 +1,71 @@
+package gobs_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/common"
+)
+
+func (s *BootstrapSuit) TestRollbackOnSetup() {
+	t := s.T()
+	ctx := context.TODO()
+	recorder := &callRecorder{}
+	bs := gobs.NewBootstrap(gobs.Config{
+		NumOfConcurrencies: gobs.DEFAULT_MAX_CONCURRENT,
+		EnableRollback:     true,
+	})
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "DB", recorder: recorder}, "DB"))
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "Cache", async: true, setupDelay: 10 * time.Millisecond, recorder: recorder}, "Cache"))
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "Repo", deps: []string{"DB"}, recorder: recorder}, "Repo"))
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "API", deps: []string{"Repo", "Cache"}, recorder: recorder}, "API"))
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "Broker", deps: []string{"API"}, setupErr: assert.AnError, recorder: recorder}, "Broker"))
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+
+	err := bs.Setup(ctx)
+	var rbErr *common.RollbackError
+	require.True(t, errors.As(err, &rbErr), "Expected error is RollbackError")
+	assert.NoError(t, rbErr.Rollback, "Expected rollback has no error")
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.ElementsMatch(t, []string{"API", "Repo", "DB", "Cache"}, rbErr.Stopped)
+	stopped := recorder.phase("stop")
+	assert.ElementsMatch(t, []string{"API", "Repo", "DB", "Cache"}, stopped, "Expected set up services are stopped")
+	assert.Equal(t, "API", stopped[0], "Expected API is stopped before its dependencies")
+	assert.Less(t, indexOf(stopped, "Repo"), indexOf(stopped, "DB"), "Expected Repo is stopped before DB")
+
+	recorder.events = nil
+	require.NoError(t, bs.Stop(ctx), "Stop expected no error")
+	assert.Empty(t, recorder.phase("stop"), "Expected rolled back services are not stopped again")
+}
+
+func (s *BootstrapSuit) TestRollbackOnStart() {
+	t := s.T()
+	ctx := context.TODO()
+	recorder := &callRecorder{}
+	bs := gobs.NewBootstrap(gobs.Config{
+		NumOfConcurrencies: 0,
+		EnableRollback:     true,
+	})
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "E1", deps: []string{"E2", "E3"}, recorder: recorder}, "E1"))
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "E2", recorder: recorder}, "E2"))
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "E3", deps: []string{"E4"}, recorder: recorder, startErr: assert.AnError}, "E3"))
+	require.NoError(t, bs.AddDefault(&KeyedService{key: "E4", recorder: recorder}, "E4"))
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+	recorder.events = nil
+
+	err := bs.Start(ctx)
+	var rbErr *common.RollbackError
+	require.True(t, errors.As(err, &rbErr), "Expected error is RollbackError")
+	assert.ErrorIs(t, err, &common.ServiceError{Service: "E3", Phase: common.StatusStart})
+	assert.Equal(t, []string{"E4", "E2"}, rbErr.Stopped)
+	assert.Equal(t, []string{"start-E2", "start-E4", "start-E3", "stop-E4", "stop-E2"}, recorder.events)
+
+	recorder.events = nil
+	require.NoError(t, bs.Stop(ctx), "Stop expected no error")
+	assert.Equal(t, []string{"stop-E1", "stop-E3"}, recorder.events, "Expected only services not rolled back are stopped")
+}
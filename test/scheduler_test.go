@@ -2,6 +2,8 @@ package gobs_test
 
 import (
 	"context"
+	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -45,11 +47,12 @@ type MockTask struct {
 	name      string
 	isAsync   bool
 	delay     time.Duration
+	err       error
 }
 
 func (m *MockTask) Run(ctx context.Context, status common.ServiceStatus) error {
 	time.Sleep(m.delay)
-	return nil
+	return m.err
 }
 func (m *MockTask) DependOn(status common.ServiceStatus) []types.ITask {
 	out := make([]types.ITask, 0, len(m.following))
@@ -198,13 +201,14 @@ func (s *SchedulerSuit) TestSchedulerWithAsyncInterruptAndStop() {
 	sched.Interrupt()
 	results, err := sched.Release()
 	assert.Equal(s.T(), context.Canceled, err)
-	require.Equal(s.T(), 4, len(results))
+	require.Equal(s.T(), 5, len(results), "Expected tasks running at the interrupt are released once finished")
 	assert.Equal(s.T(), "E", results[0].Name())
 	assert.Equal(s.T(), "A", results[1].Name())
 	assert.Equal(s.T(), "B", results[2].Name())
 	assert.Equal(s.T(), "D", results[3].Name())
+	assert.Equal(s.T(), "C", results[4].Name())
 
-	sched = scheduler.NewScheduler(
+	stopSched := scheduler.NewScheduler(
 		context.TODO(),
 		logger.NewLog(nil),
 		results,
@@ -212,13 +216,109 @@ func (s *SchedulerSuit) TestSchedulerWithAsyncInterruptAndStop() {
 		gobs.DEFAULT_MAX_CONCURRENT,
 	)
 
-	go sched.Run(context.TODO())
-	time.Sleep(500 * time.Millisecond)
-	results, err = sched.Release()
+	chErr := make(chan error, 1)
+	go func() {
+		chErr <- stopSched.Run(context.TODO())
+	}()
+	assert.Nil(s.T(), <-chErr)
+	stopped, err := stopSched.Release()
 	assert.Nil(s.T(), err)
-	require.Equal(s.T(), 4, len(results))
-	assert.Equal(s.T(), "E", results[0].Name())
-	assert.Equal(s.T(), "A", results[1].Name())
-	assert.Equal(s.T(), "B", results[2].Name())
-	assert.Equal(s.T(), "D", results[3].Name())
+	require.Equal(s.T(), 5, len(stopped))
+	assert.Equal(s.T(), "E", stopped[0].Name())
+	assert.Equal(s.T(), "A", stopped[1].Name())
+	assert.Equal(s.T(), "B", stopped[2].Name())
+	assert.Equal(s.T(), "D", stopped[3].Name())
+	assert.Equal(s.T(), "C", stopped[4].Name())
+}
+
+func (s *SchedulerSuit) TestSchedulerInterruptReleasesRunningTasks() {
+	var (
+		taskA = MockTask{name: "A", isAsync: true, delay: 100 * time.Millisecond}
+		taskB = MockTask{name: "B", isAsync: true}
+		taskC = MockTask{name: "C", following: []*MockTask{&taskA}}
+	)
+	taskA.followers = []*MockTask{&taskC}
+	sched := scheduler.NewScheduler(
+		context.TODO(),
+		logger.NewLog(nil),
+		[]types.ITask{&taskA, &taskB, &taskC},
+		common.StatusSetup,
+		gobs.DEFAULT_MAX_CONCURRENT,
+	)
+	chErr := make(chan error, 1)
+	go func() {
+		chErr <- sched.Run(context.TODO())
+	}()
+	time.Sleep(20 * time.Millisecond)
+	sched.Interrupt()
+	assert.ErrorIs(s.T(), <-chErr, context.Canceled)
+	results, err := sched.Release()
+	assert.ErrorIs(s.T(), err, context.Canceled)
+	names := make([]string, 0, len(results))
+	for _, task := range results {
+		names = append(names, task.Name())
+	}
+	assert.Equal(s.T(), []string{"B", "A"}, names, "Expected tasks which finished after the interrupt are released")
+}
+
+func (s *SchedulerSuit) TestSchedulerStopsDispatchingOnFailure() {
+	for _, numOfConcurrencies := range []int{-1, 0, 1} {
+		var (
+			taskA = MockTask{name: "A", err: assert.AnError}
+			taskB = MockTask{name: "B"}
+			taskC = MockTask{name: "C"}
+			taskD = MockTask{name: "D"}
+		)
+		sched := scheduler.NewScheduler(
+			context.TODO(),
+			logger.NewLog(nil),
+			[]types.ITask{&taskA, &taskB, &taskC, &taskD},
+			common.StatusSetup,
+			numOfConcurrencies,
+		)
+		var running []string
+		sched.Observe(func(te scheduler.TaskEvent) {
+			if te.State == scheduler.TaskRunning {
+				running = append(running, te.Task.Name())
+			}
+		})
+		err := sched.Run(context.TODO())
+		assert.ErrorIs(s.T(), err, assert.AnError)
+		results, _ := sched.Release()
+		assert.Empty(s.T(), results, "Expected queued tasks do not run after a failure")
+		assert.Equal(s.T(), []string{"A"}, running, "Expected queued tasks do not run after a failure")
+	}
+}
+
+type CountingTask struct {
+	MockTask
+	running    *atomic.Int32
+	maxRunning *atomic.Int32
+}
+
+func (c *CountingTask) Run(ctx context.Context, status common.ServiceStatus) error {
+	n := c.running.Add(1)
+	defer c.running.Add(-1)
+	for m := c.maxRunning.Load(); n > m && !c.maxRunning.CompareAndSwap(m, n); m = c.maxRunning.Load() {
+	}
+	time.Sleep(c.delay)
+	return nil
+}
+
+func (s *SchedulerSuit) TestSchedulerConcurrencyLimit() {
+	var running, maxRunning atomic.Int32
+	tasks := make([]types.ITask, 0, 8)
+	for i := 0; i < 8; i++ {
+		tasks = append(tasks, &CountingTask{
+			MockTask:   MockTask{name: fmt.Sprintf("T%d", i), isAsync: true, delay: 20 * time.Millisecond},
+			running:    &running,
+			maxRunning: &maxRunning,
+		})
+	}
+	sched := scheduler.NewScheduler(context.TODO(), logger.NewLog(nil), tasks, common.StatusSetup, 2)
+	require.NoError(s.T(), sched.Run(context.TODO()), "Run expected no error")
+	results, err := sched.Release()
+	require.NoError(s.T(), err, "Release expected no error")
+	assert.Len(s.T(), results, 8)
+	assert.Equal(s.T(), int32(2), maxRunning.Load(), "Expected async tasks are bounded by the number of concurrencies")
 }
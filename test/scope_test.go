@@ -0,0 +1,212 @@
+package gobs_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/common"
+)
+
+type ScopedLogger struct {
+	id      int
+	stopped bool
+}
+
+func (s *ScopedLogger) Stop(ctx context.Context) error {
+	s.stopped = true
+	return nil
+}
+
+type ScopedTx struct {
+	DB     *ProvidedDB
+	Logger *ScopedLogger
+	closed *[]string
+}
+
+func (s *ScopedTx) Stop(ctx context.Context) error {
+	*s.closed = append(*s.closed, "tx")
+	return nil
+}
+
+type ScopedHandler struct {
+	Tx     *ScopedTx
+	closed *[]string
+}
+
+func (s *ScopedHandler) Stop(ctx context.Context) error {
+	*s.closed = append(*s.closed, "handler")
+	return nil
+}
+
+type TransientConsumer struct {
+	Logger *ScopedLogger
+}
+
+func (s *TransientConsumer) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{
+		Deps: gobs.Dependencies{new(ScopedLogger)},
+	}, nil
+}
+
+func (s *TransientConsumer) Setup(ctx context.Context, deps ...gobs.IService) error {
+	return gobs.Dependencies(deps).Assign(&s.Logger)
+}
+
+type ScopedConsumer struct {
+	Tx *ScopedTx
+}
+
+func (s *ScopedConsumer) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{
+		Deps: gobs.Dependencies{new(ScopedTx)},
+	}, nil
+}
+
+func newLoggerProvider(counter *int) func() *ScopedLogger {
+	return func() *ScopedLogger {
+		*counter++
+		return &ScopedLogger{id: *counter}
+	}
+}
+
+func (s *BootstrapSuit) TestTransientScope() {
+	t := s.T()
+	ctx := context.TODO()
+	var counter int
+	c1, c2 := &TransientConsumer{}, &TransientConsumer{}
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.Provide(newLoggerProvider(&counter), gobs.WithScope(gobs.ScopeTransient)), "Provide expected no error")
+	require.NoError(t, bs.AddDefault(c1, "C1"), "AddDefault expected no error")
+	require.NoError(t, bs.AddDefault(c2, "C2"), "AddDefault expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+
+	assert.Equal(t, 2, counter, "Expected an instance per dependent")
+	require.NotNil(t, c1.Logger)
+	require.NotNil(t, c2.Logger)
+	assert.NotSame(t, c1.Logger, c2.Logger, "Expected dependents do not share transient instances")
+
+	require.NoError(t, bs.Start(ctx), "Start expected no error")
+	require.NoError(t, bs.Stop(ctx), "Stop expected no error")
+	assert.True(t, c1.Logger.stopped, "Expected transient instances are stopped")
+	assert.True(t, c2.Logger.stopped, "Expected transient instances are stopped")
+}
+
+func (s *BootstrapSuit) TestScopedScope() {
+	t := s.T()
+	ctx := context.TODO()
+	var counter int
+	var closed []string
+	db := &ProvidedDB{}
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(db), "AddDefault expected no error")
+	require.NoError(t, bs.Provide(newLoggerProvider(&counter), gobs.WithScope(gobs.ScopeTransient)), "Provide expected no error")
+	require.NoError(t, bs.Provide(func(db *ProvidedDB, logger *ScopedLogger) *ScopedTx {
+		return &ScopedTx{DB: db, Logger: logger, closed: &closed}
+	}, gobs.WithScope(gobs.ScopeScoped)), "Provide expected no error")
+	require.NoError(t, bs.Provide(func(tx *ScopedTx) *ScopedHandler {
+		return &ScopedHandler{Tx: tx, closed: &closed}
+	}, gobs.WithScope(gobs.ScopeScoped)), "Provide expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+
+	scope := bs.NewScope()
+	_, err := gobs.Resolve[*ScopedHandler](ctx, scope)
+	assert.True(t, errors.Is(err, common.ErrorServiceNotReady), "Expected error before Setup, got %v", err)
+
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+	assert.Equal(t, 0, counter, "Expected scoped services are not constructed by the bootstrap")
+
+	handler, err := gobs.Resolve[*ScopedHandler](ctx, scope)
+	require.NoError(t, err, "Resolve expected no error")
+	tx, err := gobs.Resolve[*ScopedTx](ctx, scope)
+	require.NoError(t, err, "Resolve expected no error")
+	assert.Same(t, tx, handler.Tx, "Expected scoped instance is shared within the scope")
+	assert.Same(t, db, tx.DB, "Expected singleton is shared with the bootstrap")
+
+	other := bs.NewScope()
+	otherTx, err := gobs.Resolve[*ScopedTx](ctx, other)
+	require.NoError(t, err, "Resolve expected no error")
+	assert.NotSame(t, tx, otherTx, "Expected scopes do not share scoped instances")
+	assert.Equal(t, 2, counter, "Expected a transient instance per scoped instance")
+
+	require.NoError(t, scope.Close(ctx), "Close expected no error")
+	assert.Equal(t, []string{"handler", "tx"}, closed, "Expected scoped instances are stopped in reverse dependency order")
+	assert.True(t, tx.Logger.stopped, "Expected transient instances of the scope are stopped")
+	assert.False(t, otherTx.Logger.stopped, "Expected other scopes are not closed")
+
+	_, err = scope.Resolve(ctx, "unknown")
+	assert.True(t, errors.Is(err, common.ErrorScopeClosed), "Expected error after Close, got %v", err)
+	require.NoError(t, other.Close(ctx), "Close expected no error")
+}
+
+func (s *BootstrapSuit) TestSingletonDependsOnScoped() {
+	t := s.T()
+	ctx := context.TODO()
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(&ScopedConsumer{}), "AddDefault expected no error")
+	require.NoError(t, bs.Provide(func() *ScopedTx {
+		return &ScopedTx{}
+	}, gobs.WithScope(gobs.ScopeScoped)), "Provide expected no error")
+	err := bs.Init(ctx)
+	assert.True(t, errors.Is(err, common.ErrorInvalidScope), "Expected scope error, got %v", err)
+}
+
+func (s *BootstrapSuit) TestTransientDependsOnScoped() {
+	t := s.T()
+	ctx := context.TODO()
+	var closed []string
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.Provide(func() *ScopedTx {
+		return &ScopedTx{closed: &closed}
+	}, gobs.WithScope(gobs.ScopeScoped)), "Provide expected no error")
+	require.NoError(t, bs.Provide(func(tx *ScopedTx) *ScopedHandler {
+		return &ScopedHandler{Tx: tx, closed: &closed}
+	}, gobs.WithScope(gobs.ScopeTransient)), "Provide expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+
+	scope := bs.NewScope()
+	tx, err := gobs.Resolve[*ScopedTx](ctx, scope)
+	require.NoError(t, err, "Resolve expected no error")
+	h1, err := gobs.Resolve[*ScopedHandler](ctx, scope)
+	require.NoError(t, err, "Resolve expected no error")
+	h2, err := gobs.Resolve[*ScopedHandler](ctx, scope)
+	require.NoError(t, err, "Resolve expected no error")
+	assert.NotSame(t, h1, h2, "Expected a transient instance per resolution")
+	assert.Same(t, tx, h1.Tx, "Expected transient gets the scoped instance of the scope")
+	assert.Same(t, tx, h2.Tx, "Expected transient gets the scoped instance of the scope")
+	require.NoError(t, scope.Close(ctx), "Close expected no error")
+	assert.Equal(t, []string{"handler", "handler", "tx"}, closed)
+
+	bs = gobs.NewBootstrap()
+	require.NoError(t, bs.Provide(func() *ScopedTx {
+		return &ScopedTx{}
+	}, gobs.WithScope(gobs.ScopeScoped)), "Provide expected no error")
+	require.NoError(t, bs.Provide(func(tx *ScopedTx) *ScopedHandler {
+		return &ScopedHandler{Tx: tx}
+	}, gobs.WithScope(gobs.ScopeTransient)), "Provide expected no error")
+	require.NoError(t, bs.Provide(func(h *ScopedHandler) *ProvidedDB {
+		return &ProvidedDB{}
+	}), "Provide expected no error")
+	err = bs.Init(ctx)
+	assert.True(t, errors.Is(err, common.ErrorInvalidScope), "Expected scope error, got %v", err)
+}
+
+func (s *BootstrapSuit) TestScopeStoppedSingleton() {
+	t := s.T()
+	ctx := context.TODO()
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(&ProvidedDB{}), "AddDefault expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+
+	scope := bs.NewScope()
+	_, err := gobs.Resolve[*ProvidedDB](ctx, scope)
+	require.NoError(t, err, "Resolve expected no error")
+	require.NoError(t, bs.Stop(ctx), "Stop expected no error")
+	_, err = gobs.Resolve[*ProvidedDB](ctx, scope)
+	assert.True(t, errors.Is(err, common.ErrorServiceNotReady), "Expected error after Stop, got %v", err)
+}
@@ -0,0 +1,175 @@
+package gobs_test
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/common"
+)
+
+type ShutdownService struct {
+	setupErr  error
+	stopDelay time.Duration
+	stopCtx   chan error
+}
+
+func (s *ShutdownService) Setup(ctx context.Context, deps ...gobs.IService) error {
+	return s.setupErr
+}
+
+func (s *ShutdownService) Stop(ctx context.Context) error {
+	s.stopCtx <- ctx.Err()
+	time.Sleep(s.stopDelay)
+	return nil
+}
+
+func (s *BootstrapSuit) TestShutdownClean() {
+	t := s.T()
+	service := &ShutdownService{stopCtx: make(chan error, 1)}
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(service), "AddDefault expected no error")
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+	defer cancel()
+	outcome, err := bs.StartBootstrap(ctx)
+	require.NoError(t, err, "StartBootstrap expected no error")
+	assert.Equal(t, gobs.ShutdownClean, outcome)
+	assert.NoError(t, <-service.stopCtx, "Expected Stop gets a context which is not cancelled")
+}
+
+func (s *BootstrapSuit) TestShutdownTimedOut() {
+	t := s.T()
+	service := &ShutdownService{stopDelay: time.Second, stopCtx: make(chan error, 1)}
+	bs := gobs.NewBootstrap(gobs.Config{
+		NumOfConcurrencies: gobs.DEFAULT_MAX_CONCURRENT,
+		ShutdownTimeout:    50 * time.Millisecond,
+	})
+	require.NoError(t, bs.AddDefault(service), "AddDefault expected no error")
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+	defer cancel()
+	begin := time.Now()
+	outcome, err := bs.StartBootstrap(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, gobs.ShutdownTimedOut, outcome)
+	assert.Less(t, time.Since(begin), 500*time.Millisecond)
+}
+
+func (s *BootstrapSuit) TestShutdownOnBootFailure() {
+	t := s.T()
+	service := &ShutdownService{setupErr: assert.AnError, stopCtx: make(chan error, 1)}
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(service), "AddDefault expected no error")
+
+	outcome, err := bs.StartBootstrap(context.TODO())
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, gobs.ShutdownClean, outcome)
+}
+
+func (s *BootstrapSuit) TestShutdownForced() {
+	t := s.T()
+	service := &ShutdownService{stopDelay: 2 * time.Second, stopCtx: make(chan error, 1)}
+	bs := gobs.NewBootstrap(gobs.Config{
+		NumOfConcurrencies:      gobs.DEFAULT_MAX_CONCURRENT,
+		ForceExitOnSecondSignal: true,
+	})
+	require.NoError(t, bs.AddDefault(service), "AddDefault expected no error")
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+		<-service.stopCtx
+		syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+	}()
+	begin := time.Now()
+	outcome, err := bs.StartBootstrap(context.TODO(), syscall.SIGUSR1)
+	require.NoError(t, err, "StartBootstrap expected no error")
+	assert.Equal(t, gobs.ShutdownForced, outcome)
+	assert.Less(t, time.Since(begin), time.Second)
+}
+
+type BootingService struct {
+	errno chan int
+}
+
+func (s *BootingService) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{
+		OnInterrupt: func(errno int) {
+			s.errno <- errno
+		},
+	}, nil
+}
+
+func (s *BootingService) Setup(ctx context.Context, deps ...gobs.IService) error {
+	select {
+	case <-common.Interrupted(ctx):
+		return context.Canceled
+	case <-time.After(2 * time.Second):
+		return nil
+	}
+}
+
+type AsyncBootService struct {
+	name       string
+	setupDelay time.Duration
+	recorder   *callRecorder
+}
+
+func (s *AsyncBootService) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{
+		AsyncMode: map[common.ServiceStatus]bool{common.StatusSetup: true},
+	}, nil
+}
+
+func (s *AsyncBootService) Setup(ctx context.Context, deps ...gobs.IService) error {
+	time.Sleep(s.setupDelay)
+	return nil
+}
+
+func (s *AsyncBootService) Stop(ctx context.Context) error {
+	s.recorder.record("stop " + s.name)
+	return nil
+}
+
+func (s *BootstrapSuit) TestShutdownDuringBoot() {
+	t := s.T()
+	service := &BootingService{errno: make(chan int, 1)}
+	bs := gobs.NewBootstrap(gobs.Config{
+		NumOfConcurrencies: gobs.DEFAULT_MAX_CONCURRENT,
+		ShutdownTimeout:    time.Second,
+	})
+	require.NoError(t, bs.AddDefault(service), "AddDefault expected no error")
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		syscall.Kill(os.Getpid(), syscall.SIGUSR2)
+	}()
+	begin := time.Now()
+	outcome, err := bs.StartBootstrap(context.TODO(), syscall.SIGUSR2)
+	assert.ErrorIs(t, err, context.Canceled, "Expected boot is interrupted")
+	assert.Equal(t, gobs.ShutdownClean, outcome)
+	assert.Less(t, time.Since(begin), time.Second, "Expected boot does not wait for Setup to finish")
+	assert.Equal(t, int(syscall.SIGUSR2), <-service.errno)
+
+	recorder := &callRecorder{}
+	bs = gobs.NewBootstrap(gobs.Config{
+		NumOfConcurrencies: gobs.DEFAULT_MAX_CONCURRENT,
+		ShutdownTimeout:    time.Second,
+	})
+	require.NoError(t, bs.AddDefault(&AsyncBootService{name: "A", setupDelay: 100 * time.Millisecond, recorder: recorder}, "A"), "AddDefault expected no error")
+	require.NoError(t, bs.AddDefault(&AsyncBootService{name: "B", recorder: recorder}, "B"), "AddDefault expected no error")
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		syscall.Kill(os.Getpid(), syscall.SIGUSR2)
+	}()
+	outcome, err = bs.StartBootstrap(context.TODO(), syscall.SIGUSR2)
+	assert.ErrorIs(t, err, context.Canceled, "Expected boot is interrupted")
+	assert.Equal(t, gobs.ShutdownClean, outcome)
+	assert.ElementsMatch(t, []string{"stop A", "stop B"}, recorder.events, "Expected the service still in Setup at the signal is stopped too")
+}
@@ -0,0 +1,88 @@
+package gobs_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/common"
+)
+
+type callRecorder struct {
+	mutex  sync.Mutex
+	events []string
+}
+
+func (r *callRecorder) record(event string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, event)
+}
+
+// phase returns the keys of the services recorded for the phase, e.g. phase("stop") for "stop-E1".
+func (r *callRecorder) phase(name string) []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	keys := []string{}
+	for _, event := range r.events {
+		if key, ok := strings.CutPrefix(event, name+"-"); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+var _ gobs.IService = (*KeyedService)(nil)
+
+// KeyedService is added with its key and depends on other keyed services by their keys.
+// Its lifecycle methods return the configured errors and are recorded as "setup-<key>", "start-<key>"
+// and "stop-<key>" when a recorder is set.
+type KeyedService struct {
+	key        string
+	deps       []string
+	async      bool
+	setupDelay time.Duration
+	setupErr   error
+	startErr   error
+	stopErr    error
+	healthErr  error
+	recorder   *callRecorder
+}
+
+func (s *KeyedService) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	sCfg := &gobs.ServiceLifeCycle{
+		AsyncMode: map[common.ServiceStatus]bool{common.StatusSetup: s.async},
+	}
+	for _, dep := range s.deps {
+		sCfg.ExtraDeps = append(sCfg.ExtraDeps, gobs.CustomService{Name: dep, Service: s})
+	}
+	return sCfg, nil
+}
+
+func (s *KeyedService) Setup(ctx context.Context, deps ...gobs.IService) error {
+	s.call("setup")
+	time.Sleep(s.setupDelay)
+	return s.setupErr
+}
+
+func (s *KeyedService) Start(ctx context.Context) error {
+	s.call("start")
+	return s.startErr
+}
+
+func (s *KeyedService) Stop(ctx context.Context) error {
+	s.call("stop")
+	return s.stopErr
+}
+
+func (s *KeyedService) Health(ctx context.Context) error {
+	return s.healthErr
+}
+
+func (s *KeyedService) call(phase string) {
+	if s.recorder != nil {
+		s.recorder.record(phase + "-" + s.key)
+	}
+}
@@ -0,0 +1,138 @@
+package gobs_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/common"
+)
+
+type SupplyConfig struct {
+	Addr string
+}
+
+// SupplyClient implements all lifecycle methods to make sure none is called when it is supplied.
+type SupplyClient struct {
+	calls []string
+}
+
+func (s *SupplyClient) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	s.calls = append(s.calls, "init")
+	return nil, nil
+}
+
+func (s *SupplyClient) Setup(ctx context.Context, deps ...gobs.IService) error {
+	s.calls = append(s.calls, "setup")
+	return nil
+}
+
+func (s *SupplyClient) Start(ctx context.Context) error {
+	s.calls = append(s.calls, "start")
+	return nil
+}
+
+func (s *SupplyClient) Stop(ctx context.Context) error {
+	s.calls = append(s.calls, "stop")
+	return nil
+}
+
+type SupplyConsumer struct {
+	Config  SupplyConfig
+	Client  *SupplyClient
+	Timeout time.Duration
+	Name    string `gobs:"inject,key=app-name"`
+}
+
+func (s *SupplyConsumer) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{
+		Deps: gobs.Dependencies{new(SupplyConfig), new(SupplyClient)},
+		ExtraDeps: []gobs.CustomService{
+			{Name: "http-timeout"},
+		},
+	}, nil
+}
+
+func (s *SupplyConsumer) Setup(ctx context.Context, deps ...gobs.IService) error {
+	return gobs.Dependencies(deps).Assign(&s.Config, &s.Client, &s.Timeout)
+}
+
+type SupplyGreeter struct {
+	Greeting string
+}
+
+func (s *SupplyGreeter) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{
+		Deps: gobs.Dependencies{new(string)},
+	}, nil
+}
+
+func (s *SupplyGreeter) Setup(ctx context.Context, deps ...gobs.IService) error {
+	return gobs.Dependencies(deps).Assign(&s.Greeting)
+}
+
+func (s *BootstrapSuit) TestSupply() {
+	t := s.T()
+	ctx := context.TODO()
+	client := &SupplyClient{}
+	consumer := &SupplyConsumer{}
+	var repoClient *SupplyClient
+	bs := gobs.NewBootstrap(gobs.Config{EnableInjection: true})
+	require.NoError(t, bs.AddDefault(consumer), "AddDefault expected no error")
+	require.NoError(t, bs.Supply(SupplyConfig{Addr: ":8080"}), "Supply expected no error")
+	require.NoError(t, bs.Supply(client), "Supply expected no error")
+	require.NoError(t, bs.Supply(5*time.Second, "http-timeout"), "Supply expected no error")
+	require.NoError(t, bs.Supply("gobs", "app-name"), "Supply expected no error")
+	require.NoError(t, bs.Provide(func(c *SupplyClient) *ProvidedRepo {
+		repoClient = c
+		return &ProvidedRepo{}
+	}), "Provide expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+	require.NoError(t, bs.Start(ctx), "Start expected no error")
+	require.NoError(t, bs.Stop(ctx), "Stop expected no error")
+
+	assert.Equal(t, ":8080", consumer.Config.Addr, "Expected supplied struct is assigned by type")
+	assert.Same(t, client, consumer.Client, "Expected supplied pointer is assigned by type")
+	assert.Same(t, client, repoClient, "Expected supplied pointer is passed to constructors")
+	assert.Equal(t, 5*time.Second, consumer.Timeout, "Expected supplied value is assigned by key")
+	assert.Equal(t, "gobs", consumer.Name, "Expected supplied value is injected by key")
+	assert.Empty(t, client.calls, "Expected supplied value skips all lifecycle phases")
+
+	g, err := bs.Graph()
+	require.NoError(t, err, "Graph expected no error")
+	assert.Len(t, g.Nodes, 6, "Expected supplied values are nodes of the graph")
+}
+
+func (s *BootstrapSuit) TestSupplyDefaultKey() {
+	t := s.T()
+	ctx := context.TODO()
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.Supply(time.Second), "Supply expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	g, err := bs.Graph()
+	require.NoError(t, err, "Graph expected no error")
+	require.Len(t, g.Nodes, 1)
+	assert.Equal(t, "time.Duration", g.Nodes[0].Key)
+
+	err = bs.Supply(nil)
+	assert.True(t, errors.Is(err, common.ErrorInvalidType), "Expected error for nil value, got %v", err)
+
+	err = bs.Supply(time.Minute)
+	assert.True(t, errors.Is(err, common.ErrorServiceExists), "Expected error for duplicate key, got %v", err)
+}
+
+func (s *BootstrapSuit) TestSupplyBuiltinByType() {
+	t := s.T()
+	ctx := context.TODO()
+	greeter := &SupplyGreeter{}
+	bs := gobs.NewBootstrap()
+	require.NoError(t, bs.AddDefault(greeter), "AddDefault expected no error")
+	require.NoError(t, bs.Supply("hello"), "Supply expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+	assert.Equal(t, "hello", greeter.Greeting, "Expected supplied builtin value is assigned by type")
+}
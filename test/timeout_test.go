@@ -0,0 +1,132 @@
+package gobs_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xarest/gobs"
+	"github.com/xarest/gobs/common"
+	"github.com/xarest/gobs/utils"
+)
+
+type SlowService struct {
+	timeouts map[common.ServiceStatus]time.Duration
+	delay    time.Duration
+	ctxErr   chan error
+	setupCtx context.Context
+}
+
+func (s *SlowService) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{Timeouts: s.timeouts}, nil
+}
+
+func (s *SlowService) Setup(ctx context.Context, deps ...gobs.IService) error {
+	s.setupCtx = ctx
+	select {
+	case <-ctx.Done():
+		if s.ctxErr != nil {
+			s.ctxErr <- ctx.Err()
+		}
+		return ctx.Err()
+	case <-time.After(s.delay):
+		return nil
+	}
+}
+
+func (s *SlowService) Start(ctx context.Context) error {
+	// Ignore the context to simulate a hung call
+	time.Sleep(s.delay)
+	return nil
+}
+
+func (s *BootstrapSuit) TestTimeout() {
+	t := s.T()
+	ctx := context.TODO()
+	service := &SlowService{
+		delay:  time.Second,
+		ctxErr: make(chan error, 1),
+		timeouts: map[common.ServiceStatus]time.Duration{
+			common.StatusSetup: 50 * time.Millisecond,
+		},
+	}
+	bs := gobs.NewBootstrap(gobs.Config{NumOfConcurrencies: gobs.DEFAULT_MAX_CONCURRENT})
+	require.NoError(t, bs.AddDefault(service), "AddDefault expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+
+	begin := time.Now()
+	err := bs.Setup(ctx)
+	assert.Less(t, time.Since(begin), 500*time.Millisecond, "Expected Setup gives up at timeout")
+	require.ErrorIs(t, err, common.ErrorTimeout, "Setup expected timeout error")
+	var timeoutErr *common.TimeoutError
+	require.True(t, errors.As(err, &timeoutErr), "Expected error is TimeoutError")
+	assert.Equal(t, utils.DefaultServiceName(service), timeoutErr.Service)
+	assert.Equal(t, common.StatusSetup, timeoutErr.Phase)
+	assert.Equal(t, 50*time.Millisecond, timeoutErr.Timeout)
+	assert.ErrorIs(t, <-service.ctxErr, context.Canceled, "Expected context of Setup is cancelled")
+}
+
+func (s *BootstrapSuit) TestDefaultTimeout() {
+	t := s.T()
+	ctx := context.TODO()
+	service := &SlowService{delay: 20 * time.Millisecond}
+	bs := gobs.NewBootstrap(gobs.Config{
+		NumOfConcurrencies: 0,
+		DefaultTimeout:     10 * time.Millisecond,
+	})
+	require.NoError(t, bs.AddDefault(service), "AddDefault expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.ErrorIs(t, bs.Setup(ctx), common.ErrorTimeout, "Setup expected timeout error")
+
+	// The timed out Setup may still be running, the service must not be changed
+	service = &SlowService{timeouts: map[common.ServiceStatus]time.Duration{
+		common.StatusSetup: time.Second,
+		common.StatusStart: time.Second,
+	}}
+	bs = gobs.NewBootstrap(gobs.Config{
+		NumOfConcurrencies: 0,
+		DefaultTimeout:     10 * time.Millisecond,
+	})
+	require.NoError(t, bs.AddDefault(service), "AddDefault expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+	require.NoError(t, bs.Start(ctx), "Start expected no error")
+	require.NoError(t, bs.Stop(ctx), "Stop expected no error")
+}
+
+type ReadyServer struct {
+	ctx chan context.Context
+}
+
+func (s *ReadyServer) Init(ctx context.Context) (*gobs.ServiceLifeCycle, error) {
+	return &gobs.ServiceLifeCycle{Timeouts: map[common.ServiceStatus]time.Duration{common.StatusStart: time.Second}}, nil
+}
+
+func (s *ReadyServer) StartServer(ctx context.Context, onReady func(err error)) error {
+	s.ctx <- ctx
+	onReady(nil)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (s *BootstrapSuit) TestTimeoutContextReleased() {
+	t := s.T()
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	service := &SlowService{timeouts: map[common.ServiceStatus]time.Duration{common.StatusSetup: time.Second}}
+	server := &ReadyServer{ctx: make(chan context.Context, 1)}
+	bs := gobs.NewBootstrap(gobs.Config{NumOfConcurrencies: 0})
+	require.NoError(t, bs.AddDefault(service), "AddDefault expected no error")
+	require.NoError(t, bs.AddDefault(server), "AddDefault expected no error")
+	require.NoError(t, bs.Init(ctx), "Init expected no error")
+	require.NoError(t, bs.Setup(ctx), "Setup expected no error")
+	assert.Error(t, service.setupCtx.Err(), "Expected context of Setup is released once Setup returns")
+
+	require.NoError(t, bs.Start(ctx), "Start expected no error")
+	serverCtx := <-server.ctx
+	assert.NoError(t, serverCtx.Err(), "Expected server keeps running after Start returns")
+	cancel()
+	<-serverCtx.Done()
+}
@@ -13,3 +13,15 @@ type ITask interface {
 	DependOn(status common.ServiceStatus) []ITask
 	Followers(status common.ServiceStatus) []ITask
 }
+
+// IPrioritizedTask is implemented by tasks which have a priority in a phase. Among tasks which are ready
+// to run at the same time, tasks with a higher priority are dispatched first. The default priority is 0.
+type IPrioritizedTask interface {
+	Priority(status common.ServiceStatus) int
+}
+
+// IResourceTask is implemented by tasks which use resource classes in a phase, e.g. database connections.
+// Resources maps each class to the weight the task uses.
+type IResourceTask interface {
+	Resources(status common.ServiceStatus) map[string]int
+}
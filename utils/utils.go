@@ -7,16 +7,31 @@ import (
 	"github.com/xarest/gobs/common"
 )
 
+// ModuleSeparator separates the names of modules from the key of a service.
+const ModuleSeparator = "::"
+
+// CompactName shortens a service key to the name of its type, keeping the modules it belongs to.
 func CompactName(name string) string {
-	names := strings.Split(name, "/")
-	return names[len(names)-1]
+	module := ""
+	if i := strings.LastIndex(name, ModuleSeparator); i >= 0 {
+		module, name = name[:i+len(ModuleSeparator)], name[i+len(ModuleSeparator):]
+	}
+	return module + name[strings.LastIndex(name, "/")+1:]
 }
 
 func DefaultServiceName(s any) string {
-	t := reflect.TypeOf(s)
+	return TypeName(reflect.TypeOf(s))
+}
+
+// TypeName returns the default key of services of the type, pointer or not. Builtin and unnamed types
+// are keyed by their name, e.g. "string" or "[]int".
+func TypeName(t reflect.Type) string {
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
+	if t.PkgPath() == "" {
+		return t.String()
+	}
 	return t.PkgPath() + "." + t.Name()
 }
 